@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/chrisgavin/gh-dispatch/internal/correlation"
+	"github.com/chrisgavin/gh-dispatch/internal/history"
+	"github.com/chrisgavin/gh-dispatch/internal/locator"
+	"github.com/chrisgavin/gh-dispatch/internal/policy"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// againPickLimit is the number of previous dispatches offered by --pick.
+const againPickLimit = 20
+
+type againFlagFields struct {
+	pick           bool
+	inputs         []string
+	noPromptInputs bool
+	noWatch        bool
+	logs           string
+	hostname       string
+	repository     string
+}
+
+var againFlags = againFlagFields{}
+
+func describeRecord(record history.Record) string {
+	description := fmt.Sprintf("%s  %s  %s@%s", record.Time.Local().Format("2006-01-02 15:04"), record.FullRepository(), record.Workflow, refs.ShortName(record.Ref))
+	if len(record.Inputs) > 0 {
+		description += "  " + formatRecordInputs(record.Inputs)
+	}
+	if record.Conclusion != "" {
+		description += fmt.Sprintf("  (%s)", record.Conclusion)
+	}
+	return description
+}
+
+// previousDispatches lists the workflow dispatches in the history that --repository, or the current repository unless picking from a list, allows, newest first.
+func previousDispatches() ([]history.Record, error) {
+	records, err := history.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := history.Filter{}
+	if againFlags.repository != "" {
+		filter.Repository = againFlags.repository
+		if againFlags.hostname != "" {
+			filter.Repository = againFlags.hostname + "/" + againFlags.repository
+		}
+	} else if !againFlags.pick {
+		currentRepository, err := repository.Current()
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to determine current repository. Use --repository or --pick instead.")
+		}
+		filter.Repository = currentRepository.Host + "/" + currentRepository.Owner + "/" + currentRepository.Name
+	}
+
+	previous := []history.Record{}
+	for index := len(records) - 1; index >= 0; index-- {
+		if records[index].Workflow != "" && filter.Matches(records[index]) {
+			previous = append(previous, records[index])
+		}
+	}
+	return previous, nil
+}
+
+var againCmd = &cobra.Command{
+	Use:   "again",
+	Short: "Dispatch the same workflow, ref and inputs as a previous dispatch, with the chance to change the inputs first.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if againFlags.hostname != "" && againFlags.repository == "" {
+			log.Error("If --hostname is specified then --repository must also be.")
+			return SilentErr
+		}
+		if err := validateLogs(againFlags.logs); err != nil {
+			log.Error(err.Error())
+			return SilentErr
+		}
+		if againFlags.logs != "" && againFlags.noWatch {
+			log.Error("--logs cannot be used together with --no-watch.")
+			return SilentErr
+		}
+
+		previous, err := previousDispatches()
+		if err != nil {
+			return err
+		}
+		if len(previous) == 0 {
+			log.Errorf("No previous dispatches found in %s.", history.Path())
+			return SilentErr
+		}
+
+		record := previous[0]
+		if againFlags.pick {
+			if len(previous) > againPickLimit {
+				previous = previous[:againPickLimit]
+			}
+			options := []string{}
+			for _, candidate := range previous {
+				options = append(options, describeRecord(candidate))
+			}
+			var index int
+			if err := survey.AskOne(&survey.Select{Message: "Which dispatch do you want to repeat?", Options: options}, &index); err != nil {
+				return errors.Wrap(err, "Unable to ask for previous dispatch.")
+			}
+			record = previous[index]
+		}
+		log.Infof("Dispatching %s on %s@%s again.", record.Workflow, record.FullRepository(), refs.ShortName(record.Ref))
+
+		currentRepository, err := repository.Parse(record.Host + "/" + record.FullRepository())
+		if err != nil {
+			return errors.Wrap(err, "Unable to parse repository.")
+		}
+		reference, err := refs.Resolve(currentRepository, record.Ref)
+		if err != nil {
+			return err
+		}
+		locator := locator.RemoteLocator{
+			Repository: currentRepository,
+			Ref:        reference,
+		}
+		workflows, err := locator.ListWorkflows()
+		if err != nil {
+			return errors.Wrap(err, "Failed to list workflows in repository.")
+		}
+		workflowData, ok := workflow.OnlyDispatchable(workflows)[record.Workflow]
+		if !ok {
+			log.Errorf("The workflow %s can no longer be dispatched on %s.", record.Workflow, refs.ShortName(reference))
+			return SilentErr
+		}
+
+		inputArguments := map[string]string{}
+		for _, input := range againFlags.inputs {
+			inputParts := strings.SplitN(input, "=", 2)
+			if len(inputParts) != 2 {
+				return errors.Errorf("Input %s is not of the form key=value.", input)
+			}
+			inputArguments[inputParts[0]] = inputParts[1]
+		}
+		// A fresh correlation ID is generated for the new dispatch rather than reusing the previous one, through the same input as before if the workflow still accepts it.
+		correlationInput, err := correlation.DetectInput(workflowData, record.CorrelationInput)
+		if err != nil {
+			log.Warnf("The workflow no longer accepts the correlation input %s, so a correlation ID will only be passed if another input is recognised.", record.CorrelationInput)
+			correlationInput, err = correlation.DetectInput(workflowData, "")
+			if err != nil {
+				return err
+			}
+		}
+
+		accepted := map[string]bool{}
+		prompter := inputPrompter{repository: currentRepository}
+		inputQuestions := []*survey.Question{}
+		inputAnswers := map[string]interface{}{}
+		for _, input := range workflowData.Inputs {
+			accepted[input.Name] = true
+			if input.Name == correlationInput {
+				continue
+			}
+			if value, ok := inputArguments[input.Name]; ok {
+				inputAnswers[input.Name] = value
+				continue
+			}
+			previousValue, hasPrevious := record.Inputs[input.Name]
+			if previousValue == history.Redacted {
+				hasPrevious = false
+				if againFlags.noPromptInputs {
+					log.Errorf("The previous value of %s was redacted from the history, so it must be given with --input.", input.Name)
+					return SilentErr
+				}
+			}
+			if againFlags.noPromptInputs {
+				if hasPrevious {
+					inputAnswers[input.Name] = previousValue
+				}
+				continue
+			}
+			defaultValue := input.Default
+			if hasPrevious {
+				defaultValue = previousValue
+			}
+			question, err := prompter.question(input, defaultValue)
+			if err != nil {
+				return err
+			}
+			inputQuestions = append(inputQuestions, question)
+		}
+		for key := range inputArguments {
+			if !accepted[key] {
+				return errors.Errorf("Input %s not accepted by workflow.", key)
+			}
+		}
+		for key := range record.Inputs {
+			if !accepted[key] {
+				log.Warnf("The workflow no longer accepts the input %s, so it will not be passed this time.", key)
+			}
+		}
+		if err := survey.Ask(inputQuestions, &inputAnswers); err != nil {
+			return errors.Wrap(err, "Unable to ask for inputs.")
+		}
+		workflowInputs, err := answersToInputs(inputAnswers)
+		if err != nil {
+			return err
+		}
+
+		if err := enforcePolicy(currentRepository, policy.Dispatch{Ref: reference, Workflow: workflowData, Inputs: workflowInputs}); err != nil {
+			return err
+		}
+		againSpec := spec.Spec{
+			Repository:       currentRepository,
+			Ref:              reference,
+			Inputs:           workflowInputs,
+			CorrelationInput: correlationInput,
+			Confirmed:        true,
+		}
+		workflowRun, err := spec.Dispatch(againSpec.WithWorkflow(workflowData))
+		if err != nil {
+			return err
+		}
+		log.Infof("Workflow run created at %s.", workflowRun.HTMLURL)
+		if againFlags.noWatch {
+			return nil
+		}
+
+		snapshot, err := watchRun(cmd.Context(), currentRepository, workflowRun, againFlags.logs)
+		if err != nil {
+			return err
+		}
+		workflowRun = &snapshot.Run
+		history.AddConclusion(currentRepository, workflowRun)
+		log.Infof("Workflow completed with conclusion %s.", workflowRun.Conclusion)
+		if workflowRun.Conclusion != "success" {
+			printFailures(currentRepository, snapshot)
+			return SilentErr
+		}
+		return nil
+	},
+}
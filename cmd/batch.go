@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/chrisgavin/gh-dispatch/internal/batch"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const defaultParallelism = 4
+
+type batchFlagFields struct {
+	parallelism int
+	hostname    string
+}
+
+var batchFlags = batchFlagFields{}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <manifest>",
+	Short: "Dispatch every workflow listed in a manifest file and watch the resulting runs.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rawManifest, err := os.ReadFile(args[0])
+		if err != nil {
+			return errors.Wrap(err, "Unable to read manifest.")
+		}
+		manifest, err := batch.ReadManifest(rawManifest)
+		if err != nil {
+			return err
+		}
+		specs, err := manifest.Specs(batchFlags.hostname)
+		if err != nil {
+			return err
+		}
+
+		parallelism := batchFlags.parallelism
+		if parallelism == 0 {
+			parallelism = manifest.Parallelism
+		}
+		if parallelism == 0 {
+			parallelism = defaultParallelism
+		}
+
+		log.Infof("Dispatching %s with a parallelism of %d...", formatCount(len(specs), "workflow"), parallelism)
+		results := spec.DispatchAll(specs, parallelism, true)
+		if err := printResults(results); err != nil {
+			return err
+		}
+		if failures := countFailures(results); failures > 0 {
+			log.Errorf("%s of %d did not succeed.", formatCount(failures, "dispatch"), len(results))
+			return SilentErr
+		}
+		return nil
+	},
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/bisect"
+	"github.com/chrisgavin/gh-dispatch/internal/commit_status"
+	"github.com/chrisgavin/gh-dispatch/internal/correlation"
+	"github.com/chrisgavin/gh-dispatch/internal/history"
+	"github.com/chrisgavin/gh-dispatch/internal/locator"
+	"github.com/chrisgavin/gh-dispatch/internal/policy"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/chrisgavin/gh-dispatch/internal/resolver"
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const bisectBranchPrefix = "gh-dispatch/bisect/"
+
+type bisectFlagFields struct {
+	good             string
+	bad              string
+	inputs           []string
+	correlationInput string
+	hostname         string
+	repository       string
+}
+
+var bisectFlags = bisectFlagFields{}
+
+// testCommit pushes a temporary branch at the commit, dispatches the workflow on it and waits for the run to complete. The branch is deleted again afterwards.
+func testCommit(ctx context.Context, baseSpec spec.Spec, sha string) (*run.WorkflowRun, error) {
+	suffix, err := correlation.NewID()
+	if err != nil {
+		return nil, err
+	}
+	branchName := bisectBranchPrefix + sha[:12] + "-" + suffix[:8]
+	if err := refs.CreateBranch(baseSpec.Repository, branchName, sha); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := refs.DeleteBranch(baseSpec.Repository, branchName); err != nil {
+			log.Warnf("Unable to delete temporary branch %s: %s", branchName, err)
+		}
+	}()
+
+	commitSpec := baseSpec
+	commitSpec.Ref = "refs/heads/" + branchName
+	workflowRun, err := spec.Dispatch(commitSpec)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Testing commit %s in %s.", sha[:12], workflowRun.HTMLURL)
+
+	for workflowRun.Status != "completed" {
+		select {
+		case <-time.After(10 * time.Second):
+		case <-ctx.Done():
+			log.Errorf("Cancelled. Run %s was left running.", workflowRun.HTMLURL)
+			return nil, SilentErr
+		}
+		workflowRun, err = run.GetRun(baseSpec.Repository, workflowRun.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	history.AddConclusion(baseSpec.Repository, workflowRun)
+	return workflowRun, nil
+}
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect <workflow>",
+	Short: "Find the first commit on which a workflow fails by dispatching it on temporary branches.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if bisectFlags.good == "" || bisectFlags.bad == "" {
+			log.Error("Both --good and --bad must be given.")
+			return SilentErr
+		}
+
+		var currentRepository repository.Repository
+		var err error
+		if bisectFlags.repository == "" {
+			if bisectFlags.hostname != "" {
+				log.Error("If --hostname is specified then --repository must also be.")
+				return SilentErr
+			}
+			currentRepository, err = repository.Current()
+			if err != nil {
+				return errors.Wrap(err, "Unable to determine current repository. Has it got a remote on GitHub?")
+			}
+		} else {
+			fullRepository := bisectFlags.repository
+			if bisectFlags.hostname != "" {
+				fullRepository = fmt.Sprintf("%s/%s", bisectFlags.hostname, fullRepository)
+			}
+			currentRepository, err = repository.Parse(fullRepository)
+			if err != nil {
+				return errors.Wrap(err, "Unable to parse repository.")
+			}
+		}
+
+		inputs := map[string]string{}
+		for _, input := range bisectFlags.inputs {
+			inputParts := strings.SplitN(input, "=", 2)
+			if len(inputParts) != 2 {
+				return errors.Errorf("Input %s is not of the form key=value.", input)
+			}
+			inputs[inputParts[0]] = inputParts[1]
+		}
+
+		goodSHA, err := commit_status.GetHeadSHA(currentRepository, bisectFlags.good)
+		if err != nil {
+			return err
+		}
+		badSHA, err := commit_status.GetHeadSHA(currentRepository, bisectFlags.bad)
+		if err != nil {
+			return err
+		}
+		commits, err := bisect.ListCommits(currentRepository, goodSHA, badSHA)
+		if err != nil {
+			return err
+		}
+
+		// The dispatch policy is enforced once up front using the workflow as of the bad commit, so that a dispatch needing confirmation only has to be confirmed once rather than for every commit.
+		locator := locator.RemoteLocator{
+			Repository: currentRepository,
+			Ref:        badSHA,
+		}
+		workflows, err := locator.ListWorkflows()
+		if err != nil {
+			return errors.Wrap(err, "Failed to list workflows in repository.")
+		}
+		workflows = workflow.OnlyDispatchable(workflows)
+		workflowName, err := resolver.ResolveWorkflow(currentRepository, workflows, args[0])
+		if err != nil {
+			return err
+		}
+		if err := enforcePolicy(currentRepository, policy.Dispatch{Ref: "refs/heads/" + bisectBranchPrefix, Workflow: workflows[workflowName], Inputs: inputs}); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		baseSpec := spec.Spec{
+			Repository:       currentRepository,
+			Workflow:         workflowName,
+			Inputs:           inputs,
+			CorrelationInput: bisectFlags.correlationInput,
+			Confirmed:        true,
+		}
+		bisection := bisect.New(commits)
+		log.Infof("Bisecting %s, which will take about %s.", formatCount(len(commits), "commit"), formatCount(bisection.Remaining(), "step"))
+		rows := [][]string{}
+		for !bisection.Done() {
+			sha := bisection.Next()
+			workflowRun, err := testCommit(ctx, baseSpec, sha)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, []string{sha[:12], workflowRun.Conclusion, workflowRun.HTMLURL})
+			verdict := "good"
+			switch workflowRun.Conclusion {
+			case "success":
+				bisection.Mark(sha, true)
+			case "failure", "timed_out":
+				verdict = "bad"
+				bisection.Mark(sha, false)
+			default:
+				if err := printTable([]string{"COMMIT", "CONCLUSION", "URL"}, rows); err != nil {
+					return err
+				}
+				log.Errorf("Commit %s completed with conclusion %s, so it is neither good nor bad. Stopping.", sha[:12], workflowRun.Conclusion)
+				return SilentErr
+			}
+			log.Infof("Commit %s is %s. About %s left.", sha[:12], verdict, formatCount(bisection.Remaining(), "step"))
+		}
+
+		if err := printTable([]string{"COMMIT", "CONCLUSION", "URL"}, rows); err != nil {
+			return err
+		}
+		log.Infof("The first bad commit is https://%s/%s/%s/commit/%s.", currentRepository.Host, currentRepository.Owner, currentRepository.Name, bisection.FirstBad())
+		return nil
+	},
+}
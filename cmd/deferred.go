@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/target"
+	"github.com/chrisgavin/gh-dispatch/internal/timing"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// deferral describes a condition that has to be met before the dispatch is sent.
+// A duration given with --in is only turned into a time once the wait starts, so that time spent answering prompts is not taken off it.
+type deferral struct {
+	at              time.Time
+	in              time.Duration
+	afterRepository repository.Repository
+	afterRunID      int64
+	waitForRun      bool
+}
+
+// parseDeferral validates the deferral flags up front, so that any mistakes are reported before the user walks away.
+func parseDeferral(currentRepository repository.Repository) (*deferral, error) {
+	deferral := deferral{}
+	if rootFlags.at != "" && rootFlags.in != 0 {
+		return nil, errors.New("Only one of --at and --in can be given.")
+	}
+	if rootFlags.at != "" {
+		at, err := timing.ParseTime(rootFlags.at, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		if at.Before(time.Now()) {
+			return nil, errors.Errorf("The time %s is in the past.", at.Format(time.RFC3339))
+		}
+		deferral.at = at
+	}
+	if rootFlags.in < 0 {
+		return nil, errors.New("The duration given with --in cannot be negative.")
+	}
+	deferral.in = rootFlags.in
+
+	if rootFlags.afterRun != "" {
+		deferral.waitForRun = true
+		deferral.afterRepository = currentRepository
+		if target.IsURL(rootFlags.afterRun) {
+			runTarget, runID, err := target.ParseRunURL(rootFlags.afterRun)
+			if err != nil {
+				return nil, err
+			}
+			deferral.afterRepository, err = repository.Parse(runTarget.Hostname + "/" + runTarget.Repository)
+			if err != nil {
+				return nil, errors.Wrap(err, "Unable to parse repository of run.")
+			}
+			deferral.afterRunID = runID
+		} else {
+			runID, err := strconv.ParseInt(rootFlags.afterRun, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("--after-run must be a run ID or URL, but got %s.", rootFlags.afterRun)
+			}
+			deferral.afterRunID = runID
+		}
+		if deferral.afterRepository.Name == "" {
+			return nil, errors.New("--after-run must be given as a URL when dispatching to multiple repositories.")
+		}
+		if _, err := run.GetRun(deferral.afterRepository, deferral.afterRunID); err != nil {
+			return nil, err
+		}
+	}
+	return &deferral, nil
+}
+
+// wait blocks until the deferral's conditions are met. Pressing Ctrl-C while waiting cancels the dispatch.
+func (deferral *deferral) wait(ctx context.Context) error {
+	if deferral.in > 0 {
+		deferral.at = time.Now().Add(deferral.in)
+	}
+	if deferral.at.IsZero() && !deferral.waitForRun {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	if !deferral.at.IsZero() {
+		log.Infof("Waiting until %s (in %s) to dispatch. Press Ctrl-C to cancel.", deferral.at.Format(time.RFC3339), time.Until(deferral.at).Round(time.Second))
+		select {
+		case <-time.After(time.Until(deferral.at)):
+		case <-ctx.Done():
+			log.Error("Cancelled. Nothing was dispatched.")
+			return SilentErr
+		}
+	}
+
+	if deferral.waitForRun {
+		log.Infof("Waiting for run %d to complete before dispatching. Press Ctrl-C to cancel.", deferral.afterRunID)
+		for {
+			workflowRun, err := run.GetRun(deferral.afterRepository, deferral.afterRunID)
+			if err != nil {
+				return err
+			}
+			if workflowRun.Status == "completed" {
+				if workflowRun.Conclusion != "success" {
+					log.Errorf("Run %d completed with conclusion %s. Nothing was dispatched.", deferral.afterRunID, workflowRun.Conclusion)
+					return SilentErr
+				}
+				log.Infof("Run %d completed successfully.", deferral.afterRunID)
+				break
+			}
+			select {
+			case <-time.After(15 * time.Second):
+			case <-ctx.Done():
+				log.Error("Cancelled. Nothing was dispatched.")
+				return SilentErr
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
+	"github.com/chrisgavin/gh-dispatch/internal/dryrun"
+	log "github.com/sirupsen/logrus"
+)
+
+func printDryRun(requests []dispatcher.Request) error {
+	format := rootFlags.printAs
+	if format == "" {
+		format = dryrun.TextFormat
+	}
+	for _, request := range requests {
+		output, err := dryrun.Format(request, format)
+		if err != nil {
+			return err
+		}
+		fmt.Println(output)
+	}
+	log.Info("Dry run complete. Nothing was dispatched.")
+	return nil
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/chrisgavin/gh-dispatch/internal/default_ref"
+	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
+	"github.com/chrisgavin/gh-dispatch/internal/history"
+	"github.com/chrisgavin/gh-dispatch/internal/locator"
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type eventFlagFields struct {
+	noWatch     bool
+	logs        string
+	payload     string
+	payloadFile string
+	hostname    string
+	repository  string
+}
+
+var eventFlags = eventFlagFields{}
+
+// eventRun is the run that a repository_dispatch event triggered for one of the workflows listening for it.
+type eventRun struct {
+	workflow string
+	run      *run.WorkflowRun
+	err      error
+}
+
+// locateEventRuns finds the run of every workflow triggered by the event. They are looked for at the same time, so that a workflow that never starts does not hold up finding the others.
+func locateEventRuns(currentRepository repository.Repository, reference string, workflowNames []string, existingRunIDs map[int64]bool) []eventRun {
+	eventRuns := make([]eventRun, len(workflowNames))
+	waitGroup := sync.WaitGroup{}
+	for index, workflowName := range workflowNames {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			workflowRun, err := run.LocateRun(currentRepository, run.Query{Event: "repository_dispatch", Reference: reference, Workflow: workflowName, ExcludedRunIDs: existingRunIDs})
+			eventRuns[index] = eventRun{workflow: workflowName, run: workflowRun, err: err}
+		}()
+	}
+	waitGroup.Wait()
+	return eventRuns
+}
+
+func readClientPayload() (json.RawMessage, error) {
+	rawPayload := []byte(eventFlags.payload)
+	if eventFlags.payloadFile != "" {
+		if eventFlags.payload != "" {
+			return nil, errors.New("Only one of --payload and --payload-file can be given.")
+		}
+		var err error
+		rawPayload, err = os.ReadFile(eventFlags.payloadFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read client payload file.")
+		}
+	}
+	if len(rawPayload) == 0 {
+		return nil, nil
+	}
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal(rawPayload, &payload); err != nil {
+		return nil, errors.Wrap(err, "Client payload must be a JSON object.")
+	}
+	return json.RawMessage(rawPayload), nil
+}
+
+var eventCmd = &cobra.Command{
+	Use:   "event [<event-type>]",
+	Short: "Send a repository_dispatch event and watch the runs it triggers.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateLogs(eventFlags.logs); err != nil {
+			log.Error(err.Error())
+			return SilentErr
+		}
+		if eventFlags.logs != "" && eventFlags.noWatch {
+			log.Error("--logs cannot be used together with --no-watch.")
+			return SilentErr
+		}
+
+		var currentRepository repository.Repository
+		var err error
+		if eventFlags.repository == "" {
+			if eventFlags.hostname != "" {
+				log.Error("If --hostname is specified then --repository must also be.")
+				return SilentErr
+			}
+			currentRepository, err = repository.Current()
+			if err != nil {
+				return errors.Wrap(err, "Unable to determine current repository. Has it got a remote on GitHub?")
+			}
+		} else {
+			fullRepository := eventFlags.repository
+			if eventFlags.hostname != "" {
+				fullRepository = fmt.Sprintf("%s/%s", eventFlags.hostname, fullRepository)
+			}
+			currentRepository, err = repository.Parse(fullRepository)
+			if err != nil {
+				return errors.Wrap(err, "Unable to parse repository.")
+			}
+		}
+
+		clientPayload, err := readClientPayload()
+		if err != nil {
+			return err
+		}
+
+		// Repository dispatch events always run the workflows on the default branch.
+		reference, err := default_ref.GetDefaultRef(currentRepository)
+		if err != nil {
+			return err
+		}
+		locator := locator.RemoteLocator{
+			Repository: currentRepository,
+			Ref:        reference,
+		}
+		workflows, err := locator.ListWorkflows()
+		if err != nil {
+			return errors.Wrap(err, "Failed to list workflows in repository.")
+		}
+		eventTypes, anyEventType := workflow.RepositoryDispatchTypes(workflows)
+		if len(eventTypes) == 0 && !anyEventType {
+			log.Error("No workflows in the repository are triggered by repository_dispatch events.")
+			return SilentErr
+		}
+
+		var eventType string
+		if len(args) == 1 {
+			eventType = args[0]
+		} else if len(eventTypes) > 0 {
+			eventTypeQuestion := &survey.Select{
+				Message: "What event type do you want to send?",
+				Options: eventTypes,
+			}
+			if err := survey.AskOne(eventTypeQuestion, &eventType); err != nil {
+				return errors.Wrap(err, "Unable to ask for event type.")
+			}
+		} else {
+			eventTypeQuestion := &survey.Input{
+				Message: "What event type do you want to send?",
+			}
+			if err := survey.AskOne(eventTypeQuestion, &eventType, survey.WithValidator(survey.Required)); err != nil {
+				return errors.Wrap(err, "Unable to ask for event type.")
+			}
+		}
+		if !anyEventType {
+			eventTypeFound := false
+			for _, declaredEventType := range eventTypes {
+				if declaredEventType == eventType {
+					eventTypeFound = true
+				}
+			}
+			if !eventTypeFound {
+				log.Errorf("No workflow is triggered by the event type %s. The declared event types are %s.", eventType, strings.Join(eventTypes, ", "))
+				return SilentErr
+			}
+		}
+
+		triggeredWorkflows := workflow.TriggeredBy(workflows, eventType)
+		// Runs that already existed before the event was sent cannot have been triggered by it, even if they were triggered by an event of the same type.
+		existingRunIDs, err := run.ListRunIDs(currentRepository, "repository_dispatch")
+		if err != nil {
+			return err
+		}
+
+		log.Info("Sending repository dispatch event...")
+		err = dispatcher.DispatchRepositoryEvent(currentRepository, eventType, clientPayload)
+		var possiblyDispatched *dispatcher.PossiblyDispatchedError
+		if errors.As(err, &possiblyDispatched) {
+			log.Warnf("%s Looking for the runs rather than sending the event again.", err)
+		} else if err != nil {
+			return err
+		}
+
+		if eventFlags.noWatch && possiblyDispatched == nil {
+			record := history.NewRecord(currentRepository, reference, "", nil, nil)
+			record.Event = eventType
+			history.Add(record)
+			return nil
+		}
+
+		log.Infof("Waiting for %s to start...", formatCount(len(triggeredWorkflows), "workflow"))
+		eventRuns := locateEventRuns(currentRepository, reference, triggeredWorkflows, existingRunIDs)
+		located := 0
+		for _, eventRun := range eventRuns {
+			if eventRun.err != nil {
+				log.Errorf("Unable to find the run of %s: %s", eventRun.workflow, eventRun.err)
+				continue
+			}
+			record := history.NewRecord(currentRepository, reference, "", nil, eventRun.run)
+			record.Event = eventType
+			history.Add(record)
+			located++
+		}
+		if possiblyDispatched != nil {
+			if located == 0 {
+				log.Errorf("The event was possibly sent, but no runs could be found for it. Check the Actions tab of %s/%s before sending it again.", currentRepository.Owner, currentRepository.Name)
+				return SilentErr
+			}
+			log.Infof("Found %s, so the event was sent after all.", formatCount(located, "run"))
+		}
+		if eventFlags.noWatch {
+			if located < len(eventRuns) {
+				return SilentErr
+			}
+			return nil
+		}
+
+		failed := located < len(eventRuns)
+		rows := [][]string{}
+		for _, eventRun := range eventRuns {
+			if eventRun.err != nil {
+				rows = append(rows, []string{eventRun.workflow, "not found", ""})
+				continue
+			}
+			snapshot, err := watchRun(cmd.Context(), currentRepository, eventRun.run, eventFlags.logs)
+			if err != nil {
+				return err
+			}
+			workflowRun := &snapshot.Run
+			history.AddConclusion(currentRepository, workflowRun)
+			log.Infof("Workflow %s completed with conclusion %s.", eventRun.workflow, workflowRun.Conclusion)
+			if workflowRun.Conclusion != "success" {
+				printFailures(currentRepository, snapshot)
+				failed = true
+			}
+			rows = append(rows, []string{eventRun.workflow, workflowRun.Conclusion, workflowRun.HTMLURL})
+		}
+		if len(eventRuns) > 1 {
+			if err := printTable([]string{"WORKFLOW", "CONCLUSION", "URL"}, rows); err != nil {
+				return err
+			}
+		}
+		if failed {
+			return SilentErr
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// failureExcerptLines is the number of lines from the end of each failed step's log that are printed when a run does not succeed.
+const failureExcerptLines = 20
+
+func lastLines(lines []run.LogLine, count int) []run.LogLine {
+	if len(lines) > count {
+		return lines[len(lines)-count:]
+	}
+	return lines
+}
+
+func formatAnnotation(annotation run.Annotation) string {
+	message := annotation.Message
+	if annotation.Title != "" {
+		message = annotation.Title + ": " + message
+	}
+	// Annotations that are not about a particular file, such as a step exiting with an error, are reported against the .github directory.
+	if annotation.Path == "" || annotation.Path == ".github" {
+		return fmt.Sprintf("%s: %s", annotation.AnnotationLevel, message)
+	}
+	location := annotation.Path
+	if annotation.StartLine > 0 {
+		location = fmt.Sprintf("%s:%d", location, annotation.StartLine)
+	}
+	return fmt.Sprintf("%s at %s: %s", annotation.AnnotationLevel, location, message)
+}
+
+// printFailures explains why a run did not succeed, by printing the end of the log of each failed step and the annotations of each failed job.
+// Failures to fetch the details are only warned about, since the run has already completed and its URL has been printed.
+func printFailures(currentRepository repository.Repository, snapshot *run.Snapshot) {
+	for _, job := range snapshot.FailedJobs() {
+		lines, err := run.GetJobLog(currentRepository, job.ID)
+		if err != nil {
+			log.Warnf("Unable to get the log of job %s: %s", job.Name, err)
+		}
+
+		failedSteps := job.FailedSteps()
+		if len(failedSteps) == 0 {
+			// Jobs can fail outside of any step, for example if no runner picked them up, in which case the end of the whole log is the most useful part.
+			fmt.Printf("\nJob %s completed with conclusion %s (%s):\n", job.Name, job.Conclusion, job.HTMLURL)
+			printExcerpt(lastLines(lines, failureExcerptLines))
+		}
+		for _, step := range failedSteps {
+			fmt.Printf("\nJob %s failed at step %s (%s):\n", job.Name, step.Name, job.HTMLURL)
+			printExcerpt(lastLines(job.StepLog(lines, step.Number), failureExcerptLines))
+		}
+
+		annotations, err := run.ListAnnotations(currentRepository, job.ID)
+		if err != nil {
+			log.Warnf("Unable to get the annotations of job %s: %s", job.Name, err)
+			continue
+		}
+		if len(annotations) > 0 {
+			fmt.Println("Annotations:")
+			for _, annotation := range annotations {
+				fmt.Printf("    %s\n", formatAnnotation(annotation))
+			}
+		}
+	}
+}
+
+func printExcerpt(lines []run.LogLine) {
+	for _, line := range lines {
+		fmt.Printf("    %s\n", strings.TrimRight(line.Text, " \t"))
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/commit_status"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// requireGreen refuses to continue unless every status and check on the head of the ref has passed, optionally waiting for pending ones.
+func requireGreen(currentRepository repository.Repository, reference string) error {
+	if !rootFlags.requireGreen && !rootFlags.waitGreen {
+		return nil
+	}
+
+	sha, err := commit_status.GetHeadSHA(currentRepository, reference)
+	if err != nil {
+		return err
+	}
+	log.Infof("Checking CI status of %s...", sha)
+	var status *commit_status.Status
+	if rootFlags.waitGreen {
+		status, err = commit_status.WaitForGreen(currentRepository, sha, rootFlags.waitGreenTimeout)
+	} else {
+		status, err = commit_status.GetStatus(currentRepository, sha)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch status.State {
+	case commit_status.Failed:
+		log.Errorf("Refusing to dispatch because checks failed on %s: %s", sha, strings.Join(status.Failed, ", "))
+		return SilentErr
+	case commit_status.Pending:
+		log.Errorf("Refusing to dispatch because checks are still pending on %s: %s. Use --wait-green to wait for them.", sha, strings.Join(status.Pending, ", "))
+		return SilentErr
+	case commit_status.NoChecks:
+		log.Errorf("Refusing to dispatch because no statuses or checks have been reported on %s yet. Use --wait-green to wait for them.", sha)
+		return SilentErr
+	}
+	log.Infof("All checks passed on %s.", sha)
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/history"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type historyFlagFields struct {
+	repository string
+	workflow   string
+	ref        string
+	conclusion string
+	since      time.Duration
+	limit      int
+	json       bool
+}
+
+var historyFlags = historyFlagFields{}
+
+func formatRecordInputs(inputs map[string]string) string {
+	keys := []string{}
+	for key := range inputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, inputs[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the workflows dispatched from this machine, newest first.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, err := history.Read()
+		if err != nil {
+			return err
+		}
+
+		filter := history.Filter{
+			Repository: historyFlags.repository,
+			Workflow:   historyFlags.workflow,
+			Ref:        historyFlags.ref,
+			Conclusion: historyFlags.conclusion,
+		}
+		if historyFlags.since > 0 {
+			filter.Since = time.Now().Add(-historyFlags.since)
+		}
+		matching := []history.Record{}
+		for index := len(records) - 1; index >= 0; index-- {
+			if historyFlags.limit > 0 && len(matching) == historyFlags.limit {
+				break
+			}
+			if filter.Matches(records[index]) {
+				matching = append(matching, records[index])
+			}
+		}
+
+		if historyFlags.json {
+			encoded, err := json.MarshalIndent(matching, "", "  ")
+			if err != nil {
+				return errors.Wrap(err, "Unable to encode history.")
+			}
+			fmt.Println(string(encoded))
+			return nil
+		}
+
+		if len(matching) == 0 {
+			log.Infof("No dispatches found in %s.", history.Path())
+			return nil
+		}
+		rows := [][]string{}
+		for _, record := range matching {
+			workflow := record.Workflow
+			if workflow == "" {
+				workflow = "repository_dispatch: " + record.Event
+			}
+			conclusion := record.Conclusion
+			if conclusion == "" {
+				conclusion = "-"
+			}
+			rows = append(rows, []string{record.Time.Local().Format("2006-01-02 15:04:05"), record.FullRepository(), refs.ShortName(record.Ref), workflow, formatRecordInputs(record.Inputs), conclusion, record.URL})
+		}
+		return printTable([]string{"TIME", "REPOSITORY", "REF", "WORKFLOW", "INPUTS", "CONCLUSION", "URL"}, rows)
+	},
+}
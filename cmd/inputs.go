@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/chrisgavin/gh-dispatch/internal/environment"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+)
+
+// inputPrompter builds the questions for workflow inputs, listing the repository's environments at most once.
+type inputPrompter struct {
+	repository   repository.Repository
+	environments []string
+}
+
+// question builds the question for an input, with the given value pre-filled.
+func (prompter *inputPrompter) question(input workflow.Input, defaultValue string) (*survey.Question, error) {
+	question := survey.Question{
+		Name: input.Name,
+	}
+	message := fmt.Sprintf("Input for %s:", input.Name)
+	switch input.Type {
+	case workflow.StringInput:
+		question.Prompt = &survey.Input{
+			Message: message,
+			Help:    input.Description,
+			Default: defaultValue,
+		}
+	case workflow.BooleanInput:
+		question.Prompt = &survey.Confirm{
+			Message: message,
+			Help:    input.Description,
+			Default: defaultValue == "true",
+		}
+	case workflow.ChoiceInput:
+		options := input.OptionProvider()
+		question.Prompt = &survey.Select{
+			Message: message,
+			Help:    input.Description,
+			Options: options,
+			Default: defaultIfDefaultOption(defaultValue, options),
+		}
+	case workflow.EnvironmentInput:
+		if prompter.environments == nil {
+			var err error
+			prompter.environments, err = environment.ListEnvironments(prompter.repository)
+			if err != nil {
+				return nil, err
+			}
+		}
+		question.Prompt = &survey.Select{
+			Message: message,
+			Help:    input.Description,
+			Options: prompter.environments,
+			Default: defaultIfDefaultOption(defaultValue, prompter.environments),
+		}
+	default:
+		return nil, errors.Errorf("Unhandled input type %s. This is a bug. :(", input.Type)
+	}
+	return &question, nil
+}
+
+// answersToInputs converts the answers to the input questions into the values to dispatch the workflow with.
+func answersToInputs(answers map[string]interface{}) (map[string]string, error) {
+	inputs := map[string]string{}
+	for key, value := range answers {
+		switch typedValue := value.(type) {
+		case string:
+			inputs[key] = typedValue
+		case survey.OptionAnswer:
+			inputs[key] = typedValue.Value
+		case bool:
+			inputs[key] = strconv.FormatBool(typedValue)
+		default:
+			return nil, errors.Errorf("Unhandled option answer type %T. This is a bug. :(", value)
+		}
+	}
+	return inputs, nil
+}
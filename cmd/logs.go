@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	logsAll    = "all"
+	logsFailed = "failed"
+)
+
+// addLogsFlag adds --logs, which can be given on its own to print the logs of every job or as --logs=failed to only print those of failed jobs.
+func addLogsFlag(cmd *cobra.Command, logs *string) {
+	cmd.Flags().StringVar(logs, "logs", "", fmt.Sprintf("Print the log of each job as it completes, or only of failed jobs with --logs=%s.", logsFailed))
+	cmd.Flags().Lookup("logs").NoOptDefVal = logsAll
+}
+
+func validateLogs(logs string) error {
+	if logs != "" && logs != logsAll && logs != logsFailed {
+		return errors.Errorf("--logs must be either %s or %s.", logsAll, logsFailed)
+	}
+	return nil
+}
+
+type jobLog struct {
+	job   run.Job
+	lines []run.LogLine
+}
+
+// jobLogCollector downloads the log of each job of a run once the job has completed, so that it can be printed while the rest of the run continues.
+type jobLogCollector struct {
+	repository repository.Repository
+	failedOnly bool
+	collected  map[int64]bool
+}
+
+func newJobLogCollector(repository repository.Repository, logs string) *jobLogCollector {
+	if logs == "" {
+		return nil
+	}
+	return &jobLogCollector{repository: repository, failedOnly: logs == logsFailed, collected: map[int64]bool{}}
+}
+
+// collect downloads the logs of the jobs that have completed since the previous snapshot.
+// Logs are sometimes not available straight after a job completes, so a job whose log cannot be downloaded is tried again on the next snapshot, unless the run has completed.
+// Warnings are returned rather than logged, so that they can be printed without disturbing the progress of the run.
+func (collector *jobLogCollector) collect(snapshot run.Snapshot) ([]jobLog, []string) {
+	jobs := snapshot.Jobs
+	if collector.failedOnly {
+		jobs = snapshot.FailedJobs()
+	}
+	logs := []jobLog{}
+	warnings := []string{}
+	for _, job := range jobs {
+		// Skipped jobs never ran, so they do not have a log.
+		if collector.collected[job.ID] || job.Status != "completed" || job.Conclusion == "skipped" {
+			continue
+		}
+		lines, err := run.GetJobLog(collector.repository, job.ID)
+		if err != nil {
+			if snapshot.Completed() {
+				warnings = append(warnings, fmt.Sprintf("Unable to get the log of job %s: %s", job.Name, err))
+				collector.collected[job.ID] = true
+			}
+			continue
+		}
+		collector.collected[job.ID] = true
+		logs = append(logs, jobLog{job: job, lines: lines})
+	}
+	return logs, warnings
+}
+
+func printJobLogs(logs []jobLog) {
+	for _, jobLog := range logs {
+		for _, line := range jobLog.lines {
+			fmt.Printf("%s | %s\n", jobLog.job.Name, line.Text)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/chrisgavin/gh-dispatch/internal/matrix"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// combinationInputs combines the inputs shared by every dispatch of a matrix with the values of one combination.
+func combinationInputs(inputs map[string]string, combination map[string]string) map[string]string {
+	combined := map[string]string{}
+	for key, value := range inputs {
+		combined[key] = value
+	}
+	for key, value := range combination {
+		combined[key] = value
+	}
+	return combined
+}
+
+// confirmMatrix checks that the matrix is within --matrix-limit and asks the user to confirm dispatching every combination of it.
+func confirmMatrix(workflowName string, inputMatrix matrix.Matrix) error {
+	size := inputMatrix.Size()
+	if size > rootFlags.matrixLimit {
+		log.Errorf("The matrix expands to %d dispatches, which is more than the limit of %d. Use --matrix-limit to raise it.", size, rootFlags.matrixLimit)
+		return SilentErr
+	}
+
+	if !rootFlags.noPromptMatrix {
+		confirmQuestion := &survey.Confirm{
+			Message: fmt.Sprintf("This will dispatch %s %s. Continue?", workflowName, formatCount(size, "time")),
+		}
+		var confirmAnswer bool
+		if err := survey.AskOne(confirmQuestion, &confirmAnswer); err != nil {
+			return errors.Wrap(err, "Unable to ask whether to dispatch the matrix.")
+		}
+		if !confirmAnswer {
+			log.Error("Aborting.")
+			return SilentErr
+		}
+	}
+	return nil
+}
+
+// dispatchMatrix dispatches every combination of the matrix, which must already have been confirmed with confirmMatrix.
+func dispatchMatrix(baseSpec spec.Spec, inputMatrix matrix.Matrix) error {
+	specs := []spec.Spec{}
+	for _, combination := range inputMatrix.Combinations() {
+		combinationSpec := baseSpec
+		combinationSpec.Inputs = combinationInputs(baseSpec.Inputs, combination)
+		specs = append(specs, combinationSpec)
+	}
+
+	log.Infof("Dispatching %s...", formatCount(len(specs), "workflow"))
+	results := spec.DispatchAll(specs, len(specs), !rootFlags.noWatch)
+	if err := printResults(results); err != nil {
+		return err
+	}
+	if rootFlags.noWatch {
+		for _, result := range results {
+			if result.Err != nil {
+				return SilentErr
+			}
+		}
+		return nil
+	}
+	if failures := countFailures(results); failures > 0 {
+		log.Errorf("%s of %d did not succeed.", formatCount(failures, "combination"), len(results))
+		return SilentErr
+	}
+	return nil
+}
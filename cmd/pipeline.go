@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/chrisgavin/gh-dispatch/internal/pipeline"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type pipelineFlagFields struct {
+	hostname string
+}
+
+var pipelineFlags = pipelineFlagFields{}
+
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Run pipelines of workflows that depend on each other.",
+}
+
+var pipelineRunCmd = &cobra.Command{
+	Use:   "run <pipeline>",
+	Short: "Dispatch each step of a pipeline once the steps it needs have succeeded.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rawPipeline, err := os.ReadFile(args[0])
+		if err != nil {
+			return errors.Wrap(err, "Unable to read pipeline.")
+		}
+		parsedPipeline, err := pipeline.ReadPipeline(rawPipeline)
+		if err != nil {
+			return err
+		}
+
+		results := parsedPipeline.Run(pipelineFlags.hostname)
+
+		rows := [][]string{}
+		failures := 0
+		for _, result := range results {
+			conclusion, url := describeResult(result.Result)
+			if !result.Succeeded() {
+				failures++
+			}
+			rows = append(rows, []string{result.Step.ID, result.Step.FormatNeeds(), result.Step.Repository, result.Step.Workflow, conclusion, url})
+		}
+		if err := printTable([]string{"STEP", "NEEDS", "REPOSITORY", "WORKFLOW", "CONCLUSION", "URL"}, rows); err != nil {
+			return err
+		}
+		if failures > 0 {
+			log.Errorf("%s of %d did not succeed.", formatCount(failures, "step"), len(results))
+			return SilentErr
+		}
+		return nil
+	},
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/chrisgavin/gh-dispatch/internal/policy"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// confirmedRepositories holds the repositories whose name the user has already typed, so that a matrix of dispatches only has to be confirmed once.
+var confirmedRepositories = map[string]bool{}
+
+// enforcePolicy checks the dispatch against the repository's dispatch policy and, if the policy requires it, asks the user to confirm the dispatch by typing the repository's name.
+func enforcePolicy(currentRepository repository.Repository, dispatch policy.Dispatch) error {
+	needsConfirmation, err := policy.Enforce(currentRepository, dispatch)
+	if err != nil {
+		log.Error(err.Error())
+		return SilentErr
+	}
+	fullName := currentRepository.Owner + "/" + currentRepository.Name
+	if !needsConfirmation || confirmedRepositories[fullName] {
+		return nil
+	}
+
+	var answer string
+	confirmQuestion := &survey.Input{
+		Message: fmt.Sprintf("The dispatch policy requires confirmation to dispatch %s. Type %s to continue:", dispatch.Workflow.Name, fullName),
+	}
+	if err := survey.AskOne(confirmQuestion, &answer); err != nil {
+		return errors.Wrap(err, "Unable to ask for confirmation.")
+	}
+	if answer != fullName {
+		log.Error("The repository name did not match. Aborting.")
+		return SilentErr
+	}
+	confirmedRepositories[fullName] = true
+	return nil
+}
+
+// confirmPolicies asks the user to confirm, all at once, the dispatches to multiple repositories whose dispatch policies require confirmation, and marks every spec as confirmed.
+// The specs are resolved in place. Specs that cannot be resolved or that their policy rejects are left for the dispatch itself to report.
+func confirmPolicies(specs []spec.Spec) error {
+	unconfirmed := []string{}
+	for index, unresolvedSpec := range specs {
+		resolvedSpec, err := spec.Resolve(unresolvedSpec)
+		if err != nil {
+			continue
+		}
+		specs[index] = resolvedSpec
+		needsConfirmation, err := spec.RequiresConfirmation(resolvedSpec)
+		fullName := resolvedSpec.Repository.Owner + "/" + resolvedSpec.Repository.Name
+		if err == nil && needsConfirmation && !confirmedRepositories[fullName] {
+			unconfirmed = append(unconfirmed, fullName)
+		}
+	}
+
+	if len(unconfirmed) > 0 {
+		// Typing the number of repositories rather than a name makes the user read how many dispatches they are confirming.
+		expected := strconv.Itoa(len(unconfirmed))
+		if len(unconfirmed) == 1 {
+			expected = unconfirmed[0]
+		}
+		var answer string
+		confirmQuestion := &survey.Input{
+			Message: fmt.Sprintf("The dispatch policies of %s require confirmation to dispatch %s. Type %s to continue:", strings.Join(unconfirmed, ", "), specs[0].Workflow, expected),
+		}
+		if err := survey.AskOne(confirmQuestion, &answer); err != nil {
+			return errors.Wrap(err, "Unable to ask for confirmation.")
+		}
+		if answer != expected {
+			log.Error("The confirmation did not match. Aborting.")
+			return SilentErr
+		}
+		for _, fullName := range unconfirmed {
+			confirmedRepositories[fullName] = true
+		}
+	}
+
+	for index := range specs {
+		specs[index].Confirmed = true
+	}
+	return nil
+}
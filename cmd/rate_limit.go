@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/rate_limit"
+	"github.com/spf13/cobra"
+)
+
+type rateLimitFlagFields struct {
+	hostname string
+}
+
+var rateLimitFlags = rateLimitFlagFields{}
+
+var rateLimitCmd = &cobra.Command{
+	Use:   "rate-limit",
+	Short: "Show how much of each API rate limit is left.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resources, err := rate_limit.GetRateLimits(rateLimitFlags.hostname)
+		if err != nil {
+			return err
+		}
+
+		names := []string{}
+		for name := range resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		rows := [][]string{}
+		for _, name := range names {
+			resource := resources[name]
+			resetsIn := max(time.Until(resource.ResetTime()).Round(time.Second), 0)
+			rows = append(rows, []string{name, strconv.Itoa(resource.Limit), strconv.Itoa(resource.Used), strconv.Itoa(resource.Remaining), resetsIn.String()})
+		}
+		return printTable([]string{"RESOURCE", "LIMIT", "USED", "REMAINING", "RESETS IN"}, rows)
+	},
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/correlation"
+	"github.com/chrisgavin/gh-dispatch/internal/flakiness"
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// dispatchRepeated dispatches the same spec several times and reports how often each job of the workflow passed, to measure how flaky it is.
+func dispatchRepeated(baseSpec spec.Spec, count int) error {
+	// Each dispatch is given its correlation ID up front, rather than as it is sent, so that the ID can be removed from the names of the jobs of its run.
+	specs := []spec.Spec{}
+	correlationIDs := []string{}
+	for range count {
+		repeatedSpec := baseSpec
+		correlationID := ""
+		if baseSpec.CorrelationInput != "" {
+			var err error
+			correlationID, err = correlation.NewID()
+			if err != nil {
+				return err
+			}
+			repeatedSpec.Inputs = map[string]string{}
+			maps.Copy(repeatedSpec.Inputs, baseSpec.Inputs)
+			repeatedSpec.Inputs[baseSpec.CorrelationInput] = correlationID
+		}
+		specs = append(specs, repeatedSpec)
+		correlationIDs = append(correlationIDs, correlationID)
+	}
+
+	log.Infof("Dispatching %s %s...", baseSpec.Workflow, formatCount(count, "time"))
+	results := spec.DispatchAll(specs, rootFlags.repeatParallelism, true)
+
+	runs := []flakiness.Run{}
+	passed := 0
+	completed := 0
+	for index, result := range results {
+		if result.Err != nil || result.Run == nil {
+			continue
+		}
+		completed++
+		if result.Succeeded() {
+			passed++
+		}
+		jobs, err := run.ListJobs(result.Spec.Repository, result.Run.ID)
+		if err != nil {
+			log.Warnf("Unable to list the jobs of %s: %s", result.Run.HTMLURL, err)
+			continue
+		}
+		runs = append(runs, flakiness.Run{CorrelationID: correlationIDs[index], Jobs: jobs})
+	}
+
+	rows := [][]string{}
+	for _, summary := range flakiness.Summarize(runs) {
+		rows = append(rows, []string{summary.Name, fmt.Sprintf("%d/%d", summary.Passed, summary.Total), fmt.Sprintf("%.0f%%", summary.PassRate()*100), strings.Join(summary.FailedURLs, " ")})
+	}
+	if err := printTable([]string{"JOB", "PASSED", "PASS RATE", "FAILURES"}, rows); err != nil {
+		return err
+	}
+
+	log.Infof("%s of %d passed.", formatCount(passed, "run"), completed)
+	if incomplete := count - completed; incomplete > 0 {
+		log.Errorf("%s could not be dispatched or watched.", formatCount(incomplete, "run"))
+	}
+	if passed < count {
+		return SilentErr
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/pkg/errors"
+)
+
+// describeResult returns the conclusion of a result and either the URL of its run or the error that prevented it from running.
+func describeResult(result spec.Result) (string, string) {
+	conclusion := "error"
+	url := ""
+	if result.Run != nil {
+		conclusion = result.Run.Conclusion
+		url = result.Run.HTMLURL
+	}
+	if errors.Is(result.Err, spec.ErrSkipped) {
+		conclusion = "skipped"
+	} else if result.Err != nil && result.Run == nil {
+		url = result.Err.Error()
+	}
+	return conclusion, url
+}
+
+func printResults(results []spec.Result) error {
+	rows := [][]string{}
+	for _, result := range results {
+		conclusion, url := describeResult(result)
+		rows = append(rows, []string{result.Spec.Repository.Owner + "/" + result.Spec.Repository.Name, result.Spec.Ref, result.Spec.Workflow, result.Spec.FormatInputs(), conclusion, url})
+	}
+	return printTable([]string{"REPOSITORY", "REF", "WORKFLOW", "INPUTS", "CONCLUSION", "URL"}, rows)
+}
+
+func printTable(header []string, rows [][]string) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		if _, err := fmt.Fprintln(table, strings.Join(row, "\t")); err != nil {
+			return errors.Wrap(err, "Unable to print table.")
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return errors.Wrap(err, "Unable to print table.")
+	}
+	return nil
+}
+
+func countFailures(results []spec.Result) int {
+	failures := 0
+	for _, result := range results {
+		if !result.Succeeded() {
+			failures++
+		}
+	}
+	return failures
+}
+
+func formatCount(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	switch {
+	case strings.HasSuffix(noun, "y"):
+		noun = strings.TrimSuffix(noun, "y") + "ies"
+	case strings.HasSuffix(noun, "ch"), strings.HasSuffix(noun, "s"):
+		noun += "es"
+	default:
+		noun += "s"
+	}
+	return strconv.Itoa(count) + " " + noun
+}
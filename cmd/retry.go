@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// watchWithRetries watches the run and, if it does not succeed, re-runs it up to --retry times. If the run was re-run then the history of its attempts is printed at the end.
+func watchWithRetries(ctx context.Context, currentRepository repository.Repository, workflowRun *run.WorkflowRun) (*run.Snapshot, error) {
+	attempts := []run.WorkflowRun{}
+	var snapshot *run.Snapshot
+	for {
+		var err error
+		snapshot, err = watchRun(ctx, currentRepository, workflowRun, rootFlags.logs)
+		if err != nil {
+			return nil, err
+		}
+		workflowRun = &snapshot.Run
+		attempts = append(attempts, *workflowRun)
+		// A cancelled run was most likely cancelled on purpose, so it is not re-run.
+		if workflowRun.Conclusion == "success" || workflowRun.Conclusion == "cancelled" || len(attempts) > rootFlags.retry {
+			break
+		}
+
+		log.Warnf("Attempt %d completed with conclusion %s. Re-running (retry %d of %d)...", workflowRun.RunAttempt, workflowRun.Conclusion, len(attempts), rootFlags.retry)
+		if err := run.Rerun(currentRepository, workflowRun.ID, rootFlags.retryFailedJobsOnly); err != nil {
+			return nil, err
+		}
+		workflowRun, err = run.WaitForAttempt(currentRepository, workflowRun.ID, workflowRun.RunAttempt+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(attempts) > 1 {
+		rows := [][]string{}
+		for _, attempt := range attempts {
+			rows = append(rows, []string{strconv.Itoa(attempt.RunAttempt), attempt.Conclusion, attempt.AttemptURL()})
+		}
+		if err := printTable([]string{"ATTEMPT", "CONCLUSION", "URL"}, rows); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
@@ -4,22 +4,28 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"sort"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/chrisgavin/gh-dispatch/internal/correlation"
 	"github.com/chrisgavin/gh-dispatch/internal/default_ref"
 	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
-	"github.com/chrisgavin/gh-dispatch/internal/environment"
+	"github.com/chrisgavin/gh-dispatch/internal/dryrun"
+	"github.com/chrisgavin/gh-dispatch/internal/history"
 	"github.com/chrisgavin/gh-dispatch/internal/local_repository"
 	"github.com/chrisgavin/gh-dispatch/internal/locator"
+	"github.com/chrisgavin/gh-dispatch/internal/matrix"
+	"github.com/chrisgavin/gh-dispatch/internal/policy"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/chrisgavin/gh-dispatch/internal/resolver"
 	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/chrisgavin/gh-dispatch/internal/target"
 	"github.com/chrisgavin/gh-dispatch/internal/version"
 	"github.com/chrisgavin/gh-dispatch/internal/workflow"
 	"github.com/cli/go-gh/v2/pkg/repository"
-	"github.com/cli/safeexec"
 	"github.com/go-git/go-git/v5"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -27,13 +33,36 @@ import (
 )
 
 type rootFlagFields struct {
-	noWatch          bool
-	inputs           []string
-	noPromptInputs   bool
-	noPromptUnpushed bool
-	hostname         string
-	repository       string
-	ref              string
+	noWatch             bool
+	inputs              []string
+	noPromptInputs      bool
+	noPromptUnpushed    bool
+	hostname            string
+	repository          string
+	ref                 string
+	url                 string
+	correlationInput    string
+	matrix              []string
+	matrixLimit         int
+	noPromptMatrix      bool
+	repos               []string
+	reposFile           string
+	waves               []int
+	maxWaveFailures     int
+	waveParallelism     int
+	dryRun              bool
+	printAs             string
+	requireGreen        bool
+	waitGreen           bool
+	waitGreenTimeout    time.Duration
+	at                  string
+	in                  time.Duration
+	afterRun            string
+	retry               int
+	retryFailedJobsOnly bool
+	repeat              int
+	repeatParallelism   int
+	logs                string
 }
 
 var rootFlags = rootFlagFields{}
@@ -52,14 +81,124 @@ func defaultIfDefaultOption(defaultValue string, options []string) string {
 	return ""
 }
 
+// applyTargetURL fills in the hostname, repository, ref and workflow from a URL given either with --url or as the positional argument.
+func applyTargetURL(args []string) ([]string, error) {
+	rawURL := rootFlags.url
+	if rawURL == "" && len(args) == 1 && target.IsURL(args[0]) {
+		rawURL = args[0]
+		args = nil
+	}
+	if rawURL == "" {
+		return args, nil
+	}
+
+	parsedTarget, err := target.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if rootFlags.hostname != "" && rootFlags.hostname != parsedTarget.Hostname {
+		return nil, errors.Errorf("The hostname %s does not match the hostname %s from the URL.", rootFlags.hostname, parsedTarget.Hostname)
+	}
+	if rootFlags.repository != "" && rootFlags.repository != parsedTarget.Repository {
+		return nil, errors.Errorf("The repository %s does not match the repository %s from the URL.", rootFlags.repository, parsedTarget.Repository)
+	}
+	rootFlags.hostname = parsedTarget.Hostname
+	rootFlags.repository = parsedTarget.Repository
+	if parsedTarget.Ref != "" {
+		if rootFlags.ref != "" && rootFlags.ref != parsedTarget.Ref {
+			return nil, errors.Errorf("The ref %s does not match the ref %s from the URL.", rootFlags.ref, parsedTarget.Ref)
+		}
+		rootFlags.ref = parsedTarget.Ref
+	}
+	if parsedTarget.Workflow != "" {
+		if len(args) > 0 {
+			return nil, errors.New("A workflow cannot be given both as an argument and in the URL.")
+		}
+		args = []string{parsedTarget.Workflow}
+	}
+	return args, nil
+}
+
 var rootCmd = &cobra.Command{
-	Use:           "gh dispatch <workflow>",
+	Use:           "dispatch [<workflow> | <url>]",
+	Annotations:   map[string]string{cobra.CommandDisplayNameAnnotation: "gh dispatch"},
 	Short:         "A GitHub CLI extension that makes it easy to dispatch GitHub Actions workflows.",
 	Version:       fmt.Sprintf("%s (%s)", version.Version(), version.Commit()),
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	Args:          cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var err error
+		args, err := applyTargetURL(args)
+		if err != nil {
+			return err
+		}
+
+		if rootFlags.printAs != "" && !rootFlags.dryRun {
+			log.Error("--print-as can only be used together with --dry-run.")
+			return SilentErr
+		}
+
+		multipleRepositories := len(rootFlags.repos) > 0 || rootFlags.reposFile != ""
+		if rootFlags.retry < 0 {
+			log.Error("--retry cannot be negative.")
+			return SilentErr
+		}
+		if rootFlags.retryFailedJobsOnly && rootFlags.retry == 0 {
+			log.Error("--retry-failed-jobs-only can only be used together with --retry.")
+			return SilentErr
+		}
+		if rootFlags.repeat < 0 {
+			log.Error("--repeat cannot be negative.")
+			return SilentErr
+		}
+		if rootFlags.repeat > 1 && (rootFlags.noWatch || rootFlags.retry > 0 || multipleRepositories || len(rootFlags.matrix) > 0) {
+			log.Error("--repeat cannot be used together with --no-watch, --retry, --matrix, --repos or --repos-file.")
+			return SilentErr
+		}
+		if rootFlags.retry > 0 && (rootFlags.noWatch || multipleRepositories || len(rootFlags.matrix) > 0) {
+			log.Error("--retry cannot be used together with --no-watch, --matrix, --repos or --repos-file.")
+			return SilentErr
+		}
+		if err := validateLogs(rootFlags.logs); err != nil {
+			log.Error(err.Error())
+			return SilentErr
+		}
+		if rootFlags.logs != "" && (rootFlags.noWatch || rootFlags.repeat > 1 || multipleRepositories || len(rootFlags.matrix) > 0) {
+			log.Error("--logs cannot be used together with --no-watch, --repeat, --matrix, --repos or --repos-file.")
+			return SilentErr
+		}
+		if (rootFlags.hostname != "") && (rootFlags.repository == "") && !multipleRepositories {
+			log.Error("If --hostname is specified then --repository must also be.")
+			return SilentErr
+		}
+
+		if multipleRepositories {
+			if rootFlags.repository != "" {
+				log.Error("--repository cannot be used together with --repos or --repos-file.")
+				return SilentErr
+			}
+			// These flags only apply when dispatching to a single repository, so they are rejected rather than silently ignored.
+			for _, flag := range []string{"matrix", "matrix-limit", "no-prompt-matrix", "no-prompt-unpushed"} {
+				if cmd.Flags().Changed(flag) {
+					log.Errorf("--%s cannot be used together with --repos or --repos-file.", flag)
+					return SilentErr
+				}
+			}
+			if len(args) != 1 {
+				log.Error("A workflow must be given when dispatching to multiple repositories.")
+				return SilentErr
+			}
+			inputs := map[string]string{}
+			for _, input := range rootFlags.inputs {
+				inputParts := strings.SplitN(input, "=", 2)
+				if len(inputParts) != 2 {
+					return errors.Errorf("Input %s is not of the form key=value.", input)
+				}
+				inputs[inputParts[0]] = inputParts[1]
+			}
+			return dispatchWaves(cmd.Context(), args[0], inputs)
+		}
+
 		var workflows map[string]workflow.Workflow
 		var currentRepository repository.Repository
 		var reference string
@@ -80,7 +219,10 @@ var rootCmd = &cobra.Command{
 					return err
 				}
 			} else {
-				reference = rootFlags.ref
+				reference, err = refs.Resolve(currentRepository, rootFlags.ref)
+				if err != nil {
+					return err
+				}
 			}
 			if len(remoteReferenceWarnings) > 0 && !rootFlags.noPromptUnpushed {
 				antepenultimateIndex := len(remoteReferenceWarnings) - 2
@@ -116,12 +258,13 @@ var rootCmd = &cobra.Command{
 			if err != nil {
 				return errors.Wrap(err, "Unable to parse repository.")
 			}
-			reference = rootFlags.ref
-			if reference == "" {
+			if rootFlags.ref == "" {
 				reference, err = default_ref.GetDefaultRef(currentRepository)
-				if err != nil {
-					return err
-				}
+			} else {
+				reference, err = refs.Resolve(currentRepository, rootFlags.ref)
+			}
+			if err != nil {
+				return err
 			}
 			locator := locator.RemoteLocator{
 				Repository: currentRepository,
@@ -132,6 +275,7 @@ var rootCmd = &cobra.Command{
 				return errors.Wrap(err, "Failed to list workflows in repository.")
 			}
 		}
+		workflows = workflow.OnlyDispatchable(workflows)
 
 		if len(workflows) == 0 {
 			log.Error("No dispatchable workflows found in repository.")
@@ -154,14 +298,26 @@ var rootCmd = &cobra.Command{
 				return errors.Wrap(err, "Unable to ask for workflow.")
 			}
 		} else if len(args) == 1 {
-			workflowPathParts := strings.Split(args[0], "/")
-			workflowName = workflowPathParts[len(workflowPathParts)-1]
+			workflowName, err = resolver.ResolveWorkflow(currentRepository, workflows, args[0])
+			if err != nil {
+				var unknownWorkflowError *resolver.UnknownWorkflowError
+				if errors.As(err, &unknownWorkflowError) {
+					log.Error(unknownWorkflowError.Error())
+					return SilentErr
+				}
+				return err
+			}
 		} else {
 			return errors.New("Too many arguments.")
 		}
 
 		workflowData := workflows[workflowName]
 
+		deferral, err := parseDeferral(currentRepository)
+		if err != nil {
+			return err
+		}
+
 		inputArguments := map[string]string{}
 		for _, input := range rootFlags.inputs {
 			inputParts := strings.SplitN(input, "=", 2)
@@ -179,106 +335,192 @@ var rootCmd = &cobra.Command{
 			inputArguments[key] = value
 		}
 
-		var environmentCache []string
+		correlationInput, err := correlation.DetectInput(workflowData, rootFlags.correlationInput)
+		if err != nil {
+			return err
+		}
+		inputMatrix, err := matrix.Parse(rootFlags.matrix)
+		if err != nil {
+			return err
+		}
+		matrixKeys := map[string]bool{}
+		for _, dimension := range inputMatrix {
+			inputFound := false
+			for _, input := range workflowData.Inputs {
+				if input.Name == dimension.Key {
+					inputFound = true
+				}
+			}
+			if !inputFound {
+				return errors.Errorf("Matrix input %s not accepted by workflow.", dimension.Key)
+			}
+			if _, ok := inputArguments[dimension.Key]; ok || dimension.Key == correlationInput {
+				return errors.Errorf("Input %s cannot be both given a value and varied by the matrix.", dimension.Key)
+			}
+			matrixKeys[dimension.Key] = true
+		}
+
+		// When dispatching more than once, each dispatch is given its own correlation ID as it is sent.
+		dispatchesMany := len(inputMatrix) > 0 || rootFlags.repeat > 1
+		var correlationID string
+		if correlationInput != "" {
+			if value, ok := inputArguments[correlationInput]; ok {
+				if dispatchesMany {
+					return errors.New("A correlation ID cannot be given when dispatching a matrix or repeating a dispatch, as each dispatch needs its own.")
+				}
+				correlationID = value
+			} else if !dispatchesMany {
+				correlationID, err = correlation.NewID()
+				if err != nil {
+					return err
+				}
+				inputArguments[correlationInput] = correlationID
+			}
+		}
+
+		prompter := inputPrompter{repository: currentRepository}
 		inputQuestions := []*survey.Question{}
 		inputAnswers := map[string]interface{}{}
 		for _, input := range workflowData.Inputs {
+			if matrixKeys[input.Name] || (dispatchesMany && input.Name == correlationInput) {
+				continue
+			}
 			if inputValue, ok := inputArguments[input.Name]; ok {
 				inputAnswers[input.Name] = inputValue
 			} else if !rootFlags.noPromptInputs {
-				question := survey.Question{
-					Name: input.Name,
-				}
-				message := fmt.Sprintf("Input for %s:", input.Name)
-				switch input.Type {
-				case workflow.StringInput:
-					question.Prompt = &survey.Input{
-						Message: message,
-						Help:    input.Description,
-						Default: input.Default,
-					}
-				case workflow.BooleanInput:
-					question.Prompt = &survey.Confirm{
-						Message: message,
-						Help:    input.Description,
-						Default: input.Default == "true",
-					}
-				case workflow.ChoiceInput:
-					options := input.OptionProvider()
-					question.Prompt = &survey.Select{
-						Message: message,
-						Help:    input.Description,
-						Options: options,
-						Default: defaultIfDefaultOption(input.Default, options),
-					}
-				case workflow.EnvironmentInput:
-					if environmentCache == nil {
-						environmentCache, err = environment.ListEnvironments(currentRepository)
-						if err != nil {
-							return err
-						}
-					}
-					question.Prompt = &survey.Select{
-						Message: message,
-						Help:    input.Description,
-						Options: environmentCache,
-						Default: defaultIfDefaultOption(input.Default, environmentCache),
-					}
-				default:
-					return errors.Errorf("Unhandled input type %s. This is a bug. :(", input.Type)
+				question, err := prompter.question(input, input.Default)
+				if err != nil {
+					return err
 				}
-				inputQuestions = append(inputQuestions, &question)
+				inputQuestions = append(inputQuestions, question)
 			}
 		}
 		if err := survey.Ask(inputQuestions, &inputAnswers); err != nil {
 			return errors.Wrap(err, "Unable to ask for inputs.")
 		}
-		workflowInputs := map[string]string{}
-		for key, value := range inputAnswers {
-			switch typedValue := value.(type) {
-			case string:
-				workflowInputs[key] = typedValue
-			case survey.OptionAnswer:
-				workflowInputs[key] = typedValue.Value
-			case bool:
-				workflowInputs[key] = strconv.FormatBool(typedValue)
-			default:
-				return errors.Errorf("Unhandled option answer type %T. This is a bug. :(", value)
-			}
-		}
-
-		log.Info("Dispatching workflow...")
-		err = dispatcher.DispatchWorkflow(currentRepository, reference, workflowName, workflowInputs)
+		workflowInputs, err := answersToInputs(inputAnswers)
 		if err != nil {
 			return err
 		}
 
-		if !rootFlags.noWatch {
-			log.Info("Waiting for workflow to start...")
-			workflowRun, err := run.LocateRun(currentRepository, reference)
-			if err != nil {
+		if rootFlags.dryRun {
+			requests := []dispatcher.Request{}
+			if len(inputMatrix) > 0 {
+				for _, combination := range inputMatrix.Combinations() {
+					requests = append(requests, dispatcher.WorkflowDispatchRequest(currentRepository, reference, workflowName, combinationInputs(workflowInputs, combination)))
+				}
+			} else {
+				requests = append(requests, dispatcher.WorkflowDispatchRequest(currentRepository, reference, workflowName, workflowInputs))
+			}
+			return printDryRun(requests)
+		}
+
+		// The matrix is confirmed and the dispatch policy is enforced before any deferral, so that a dispatch needing confirmation is not left waiting for someone to type it.
+		dispatchInputs := []map[string]string{workflowInputs}
+		if len(inputMatrix) > 0 {
+			if err := confirmMatrix(workflowName, inputMatrix); err != nil {
 				return err
 			}
+			dispatchInputs = nil
+			for _, combination := range inputMatrix.Combinations() {
+				dispatchInputs = append(dispatchInputs, combinationInputs(workflowInputs, combination))
+			}
+		}
+		for _, inputs := range dispatchInputs {
+			policyDispatch := policy.Dispatch{
+				Ref:                  reference,
+				Workflow:             workflowData,
+				Inputs:               inputs,
+				SkippedUnpushedCheck: rootFlags.noPromptUnpushed && rootFlags.repository == "",
+			}
+			if err := enforcePolicy(currentRepository, policyDispatch); err != nil {
+				return err
+			}
+		}
 
-			ghPath, err := safeexec.LookPath("gh")
-			if err != nil {
-				return errors.Wrap(err, "Unable to find gh.")
+		if err := deferral.wait(cmd.Context()); err != nil {
+			return err
+		}
+
+		if err := requireGreen(currentRepository, reference); err != nil {
+			return err
+		}
+
+		if len(inputMatrix) > 0 {
+			baseSpec := spec.Spec{
+				Repository:       currentRepository,
+				Ref:              reference,
+				Inputs:           workflowInputs,
+				CorrelationInput: correlationInput,
+				Confirmed:        true,
+			}
+			return dispatchMatrix(baseSpec.WithWorkflow(workflowData), inputMatrix)
+		}
+		if rootFlags.repeat > 1 {
+			baseSpec := spec.Spec{
+				Repository:       currentRepository,
+				Ref:              reference,
+				Inputs:           workflowInputs,
+				CorrelationInput: correlationInput,
+				Confirmed:        true,
 			}
+			return dispatchRepeated(baseSpec.WithWorkflow(workflowData), rootFlags.repeat)
+		}
 
-			command := exec.CommandContext(cmd.Context(), ghPath, "run", "watch", "--repo", fmt.Sprintf("%s/%s/%s", currentRepository.Host, currentRepository.Owner, currentRepository.Name), strconv.FormatInt(workflowRun.ID, 10))
-			command.Stdout = os.Stdout
-			command.Stderr = os.Stderr
-			err = command.Run()
+		log.Info("Dispatching workflow...")
+		runDetails, err := dispatcher.DispatchWorkflow(currentRepository, reference, workflowName, workflowInputs)
+		var possiblyDispatched *dispatcher.PossiblyDispatchedError
+		if errors.As(err, &possiblyDispatched) {
+			log.Warnf("%s Looking for the run rather than dispatching again.", err)
+		} else if err != nil {
+			return err
+		}
+		if runDetails != nil {
+			log.Infof("Workflow run created at %s.", runDetails.HTMLURL)
+		}
+
+		if rootFlags.noWatch && possiblyDispatched == nil {
+			var workflowRun *run.WorkflowRun
+			if runDetails != nil {
+				workflowRun = &run.WorkflowRun{ID: runDetails.WorkflowRunID, HTMLURL: runDetails.HTMLURL}
+			}
+			record := history.NewRecord(currentRepository, reference, workflowName, workflowInputs, workflowRun)
+			record.CorrelationInput = correlationInput
+			history.Add(record)
+		} else {
+			var workflowRun *run.WorkflowRun
+			if runDetails != nil {
+				workflowRun, err = run.GetRun(currentRepository, runDetails.WorkflowRunID)
+			} else {
+				log.Info("Waiting for workflow to start...")
+				workflowRun, err = run.LocateRun(currentRepository, run.Query{Reference: reference, Workflow: workflowName, CorrelationID: correlationID})
+			}
 			if err != nil {
-				return errors.Wrap(err, "Unable to watch workflow progress.")
+				if possiblyDispatched != nil {
+					log.Errorf("The workflow was possibly dispatched, but no run could be found for it. Check the Actions tab of %s/%s before dispatching it again.", currentRepository.Owner, currentRepository.Name)
+					return SilentErr
+				}
+				return err
+			}
+			record := history.NewRecord(currentRepository, reference, workflowName, workflowInputs, workflowRun)
+			record.CorrelationInput = correlationInput
+			history.Add(record)
+			if possiblyDispatched != nil {
+				log.Infof("Found the run at %s, so the workflow was dispatched after all.", workflowRun.HTMLURL)
+				if rootFlags.noWatch {
+					return nil
+				}
 			}
 
-			workflowRun, err = run.GetRun(currentRepository, workflowRun.ID)
+			snapshot, err := watchWithRetries(cmd.Context(), currentRepository, workflowRun)
 			if err != nil {
 				return err
 			}
+			workflowRun = &snapshot.Run
+			history.AddConclusion(currentRepository, workflowRun)
 			log.Infof("Workflow completed with conclusion %s.", workflowRun.Conclusion)
 			if workflowRun.Conclusion != "success" {
+				printFailures(currentRepository, snapshot)
 				os.Exit(1)
 			}
 		}
@@ -305,21 +547,85 @@ func Execute(ctx context.Context) error {
 	rootCmd.Flags().BoolVar(&rootFlags.noPromptUnpushed, "no-prompt-unpushed", false, "Do not warn about any uncommitted or unpushed changes.")
 	rootCmd.Flags().StringVar(&rootFlags.hostname, "hostname", "", "The hostname of the GitHub instance.")
 	rootCmd.Flags().StringVar(&rootFlags.repository, "repository", "", "The repository to dispatch the workflow on.")
-	rootCmd.Flags().StringVar(&rootFlags.ref, "ref", "", "The reference to dispatch the workflow on.")
+	rootCmd.Flags().StringVar(&rootFlags.ref, "ref", "", "The branch, tag or commit to dispatch the workflow on.")
+	rootCmd.Flags().StringVar(&rootFlags.url, "url", "", "A GitHub URL to a repository, branch or workflow to dispatch.")
+	rootCmd.Flags().StringVar(&rootFlags.correlationInput, "correlation-input", "", "The input to pass a unique ID through so the run can be identified. Inputs named dispatch_id, correlation_id or distinct_id are used automatically.")
+
+	rootCmd.Flags().StringArrayVar(&rootFlags.matrix, "matrix", nil, "Dispatch the workflow once for every combination of input values, as `key=value1,value2`.")
+	rootCmd.Flags().IntVar(&rootFlags.matrixLimit, "matrix-limit", 20, "The maximum number of dispatches a matrix may expand to.")
+	rootCmd.Flags().BoolVar(&rootFlags.noPromptMatrix, "no-prompt-matrix", false, "Do not ask for confirmation before dispatching a matrix.")
+	rootCmd.Flags().StringSliceVar(&rootFlags.repos, "repos", nil, "Dispatch the workflow on each of these repositories, as `owner/repo`.")
+	rootCmd.Flags().StringVar(&rootFlags.reposFile, "repos-file", "", "Dispatch the workflow on each repository listed in this file, one per line.")
+	rootCmd.Flags().IntSliceVar(&rootFlags.waves, "waves", nil, "The sizes of the waves to dispatch to multiple repositories in, with the remaining repositories in a final wave.")
+	rootCmd.Flags().IntVar(&rootFlags.maxWaveFailures, "max-wave-failures", 0, "The number of runs in a wave that may fail before the rollout is stopped.")
+	rootCmd.Flags().IntVar(&rootFlags.waveParallelism, "wave-parallelism", defaultParallelism, "The maximum number of repositories in a wave to dispatch and watch at once.")
+	rootCmd.Flags().BoolVar(&rootFlags.dryRun, "dry-run", false, "Resolve the workflow and inputs and print the dispatch request instead of sending it.")
+	rootCmd.Flags().StringVar(&rootFlags.printAs, "print-as", "", fmt.Sprintf("The format to print a dry run in, one of %s.", strings.Join(dryrun.Formats, ", ")))
+	rootCmd.Flags().BoolVar(&rootFlags.requireGreen, "require-green", false, "Refuse to dispatch unless every status and check on the ref has passed.")
+	rootCmd.Flags().BoolVar(&rootFlags.waitGreen, "wait-green", false, "Wait for pending statuses and checks on the ref to pass before dispatching, failing if any of them fail.")
+	rootCmd.Flags().DurationVar(&rootFlags.waitGreenTimeout, "wait-green-timeout", time.Hour, "How long to wait for statuses and checks with --wait-green.")
+	rootCmd.Flags().StringVar(&rootFlags.at, "at", "", "Wait until this `time` before dispatching, such as 2026-10-18T02:00Z.")
+	rootCmd.Flags().DurationVar(&rootFlags.in, "in", 0, "Wait for this `duration` before dispatching, such as 2h.")
+	rootCmd.Flags().StringVar(&rootFlags.afterRun, "after-run", "", "Wait for this run, given as an ID or URL, to complete successfully before dispatching.")
+	rootCmd.Flags().IntVar(&rootFlags.retry, "retry", 0, "Re-run the workflow up to this many times if it does not succeed.")
+	rootCmd.Flags().BoolVar(&rootFlags.retryFailedJobsOnly, "retry-failed-jobs-only", false, "Only re-run the jobs that failed when retrying, rather than the whole workflow.")
+	rootCmd.Flags().IntVar(&rootFlags.repeat, "repeat", 0, "Dispatch the workflow this many times and report how often each job passed.")
+	rootCmd.Flags().IntVar(&rootFlags.repeatParallelism, "repeat-parallelism", defaultParallelism, "The maximum number of repeated dispatches to run at once.")
+	addLogsFlag(rootCmd, &rootFlags.logs)
+
+	batchCmd.Flags().IntVar(&batchFlags.parallelism, "parallelism", 0, "The maximum number of workflows to dispatch and watch at once. Defaults to the manifest's parallelism, or 4.")
+	batchCmd.Flags().StringVar(&batchFlags.hostname, "hostname", "", "The hostname of the GitHub instance for repositories that do not specify one.")
+	rootCmd.AddCommand(batchCmd)
+
+	eventCmd.Flags().BoolVar(&eventFlags.noWatch, "no-watch", false, "Do not wait for the workflow to complete.")
+	addLogsFlag(eventCmd, &eventFlags.logs)
+	eventCmd.Flags().StringVar(&eventFlags.payload, "payload", "", "The client payload to send with the event, as a JSON object.")
+	eventCmd.Flags().StringVar(&eventFlags.payloadFile, "payload-file", "", "A file containing the client payload to send with the event, as a JSON object.")
+	eventCmd.Flags().StringVar(&eventFlags.hostname, "hostname", "", "The hostname of the GitHub instance.")
+	eventCmd.Flags().StringVar(&eventFlags.repository, "repository", "", "The repository to send the event to.")
+	rootCmd.AddCommand(eventCmd)
+
+	pipelineRunCmd.Flags().StringVar(&pipelineFlags.hostname, "hostname", "", "The hostname of the GitHub instance for repositories that do not specify one.")
+	pipelineCmd.AddCommand(pipelineRunCmd)
+	rootCmd.AddCommand(pipelineCmd)
+
+	bisectCmd.Flags().StringVar(&bisectFlags.good, "good", "", "A commit, branch or tag on which the workflow succeeds.")
+	bisectCmd.Flags().StringVar(&bisectFlags.bad, "bad", "", "A later commit, branch or tag on which the workflow fails.")
+	bisectCmd.Flags().StringSliceVar(&bisectFlags.inputs, "input", nil, "Inputs to pass to the workflow on every commit, as `key=value`.")
+	bisectCmd.Flags().StringVar(&bisectFlags.correlationInput, "correlation-input", "", "The input to pass a unique ID through so each run can be identified.")
+	bisectCmd.Flags().StringVar(&bisectFlags.hostname, "hostname", "", "The hostname of the GitHub instance.")
+	bisectCmd.Flags().StringVar(&bisectFlags.repository, "repository", "", "The repository to bisect.")
+	rootCmd.AddCommand(bisectCmd)
+
+	historyCmd.Flags().StringVar(&historyFlags.repository, "repository", "", "Only show dispatches to this repository, as `owner/repo`.")
+	historyCmd.Flags().StringVar(&historyFlags.workflow, "workflow", "", "Only show dispatches of this workflow file or repository_dispatch event type.")
+	historyCmd.Flags().StringVar(&historyFlags.ref, "ref", "", "Only show dispatches on this branch or tag.")
+	historyCmd.Flags().StringVar(&historyFlags.conclusion, "conclusion", "", "Only show dispatches whose runs completed with this conclusion.")
+	historyCmd.Flags().DurationVar(&historyFlags.since, "since", 0, "Only show dispatches made within this `duration`, such as 24h.")
+	historyCmd.Flags().IntVar(&historyFlags.limit, "limit", 20, "The maximum number of dispatches to show, or 0 for all of them.")
+	historyCmd.Flags().BoolVar(&historyFlags.json, "json", false, "Print the dispatches as JSON.")
+	rootCmd.AddCommand(historyCmd)
+
+	againCmd.Flags().BoolVar(&againFlags.pick, "pick", false, "Choose from a list of recent dispatches rather than repeating the latest one.")
+	againCmd.Flags().StringSliceVar(&againFlags.inputs, "input", nil, "Inputs to change from the previous dispatch, as `key=value`.")
+	againCmd.Flags().BoolVar(&againFlags.noPromptInputs, "no-prompt-inputs", false, "Do not prompt for any inputs, reusing the previous values.")
+	againCmd.Flags().BoolVar(&againFlags.noWatch, "no-watch", false, "Do not wait for the workflow to complete.")
+	addLogsFlag(againCmd, &againFlags.logs)
+	againCmd.Flags().StringVar(&againFlags.hostname, "hostname", "", "The hostname of the GitHub instance.")
+	againCmd.Flags().StringVar(&againFlags.repository, "repository", "", "Repeat the latest dispatch to this repository rather than the current one.")
+	rootCmd.AddCommand(againCmd)
+
+	rateLimitCmd.Flags().StringVar(&rateLimitFlags.hostname, "hostname", "", "The hostname of the GitHub instance.")
+	rootCmd.AddCommand(rateLimitCmd)
+
+	scheduleCmd.Flags().StringVar(&scheduleFlags.hostname, "hostname", "", "The hostname of the GitHub instance for repositories that do not specify one.")
+	scheduleCmd.Flags().StringVar(&scheduleFlags.logFile, "log-file", "", "The file to log each dispatch and its conclusion to. Defaults to the config's log, or "+defaultScheduleLog+".")
+	rootCmd.AddCommand(scheduleCmd)
 
 	err := rootFlags.Init(rootCmd)
 	if err != nil {
 		return err
 	}
 
-	if (rootFlags.hostname != "") && (rootFlags.repository == "") {
-		log.Error("If --hostname is specified then --repository must also be.")
-		return SilentErr
-	}
-
-	if (rootFlags.ref != "") && !strings.HasPrefix(rootFlags.ref, "refs/") {
-		rootFlags.ref = fmt.Sprintf("refs/heads/%s", rootFlags.ref)
-	}
-
 	return rootCmd.ExecuteContext(ctx)
 }
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/chrisgavin/gh-dispatch/internal/scheduler"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const defaultScheduleLog = "gh-dispatch-schedule.log"
+
+type scheduleFlagFields struct {
+	hostname string
+	logFile  string
+}
+
+var scheduleFlags = scheduleFlagFields{}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule <config>",
+	Short: "Dispatch workflows on cron schedules from a config file, running in the foreground until interrupted.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rawConfig, err := os.ReadFile(args[0])
+		if err != nil {
+			return errors.Wrap(err, "Unable to read schedule config.")
+		}
+		config, err := scheduler.ReadConfig(rawConfig)
+		if err != nil {
+			return err
+		}
+
+		logPath := scheduleFlags.logFile
+		if logPath == "" {
+			logPath = config.Log
+		}
+		if logPath == "" {
+			logPath = defaultScheduleLog
+		}
+		logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return errors.Wrap(err, "Unable to open schedule log.")
+		}
+		defer func() {
+			if err := logFile.Close(); err != nil {
+				log.Warnf("Unable to close schedule log: %s", err)
+			}
+		}()
+
+		logger := log.New()
+		logger.SetFormatter(&log.TextFormatter{FullTimestamp: true, DisableColors: true})
+		logger.SetOutput(logFile)
+		logger.AddHook(&consoleHook{})
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		log.Infof("Running %s. Logging to %s. Press Ctrl-C to stop.", formatCount(len(config.Schedules), "schedule"), logPath)
+		config.Run(ctx, scheduleFlags.hostname, logger)
+		return nil
+	},
+}
+
+// consoleHook copies the scheduler's log entries to the standard logger so that they are shown on the console as well as written to the log file.
+type consoleHook struct{}
+
+func (hook *consoleHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (hook *consoleHook) Fire(entry *log.Entry) error {
+	log.WithFields(entry.Data).Log(entry.Level, entry.Message)
+	return nil
+}
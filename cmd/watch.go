@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal, or 0 if it is unknown.
+func terminalWidth(file *os.File) int {
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// watchRun shows the progress of the run until it completes and returns its final state.
+// On a terminal the progress is redrawn in place after every poll. Otherwise it is printed again whenever the state of a job or step changes.
+// If logs is all or failed then the log of each job, or of each failed job, is printed above the progress as the job completes.
+func watchRun(ctx context.Context, currentRepository repository.Repository, workflowRun *run.WorkflowRun, logs string) (*run.Snapshot, error) {
+	log.Infof("Watching %s.", workflowRun.HTMLURL)
+	interactive := isTerminal(os.Stdout)
+	collector := newJobLogCollector(currentRepository, logs)
+	renderedLines := 0
+	previousState := ""
+	watcher := run.Watcher{
+		Repository: currentRepository,
+		OnUpdate: func(snapshot run.Snapshot) {
+			jobLogs := []jobLog{}
+			warnings := []string{}
+			if collector != nil {
+				jobLogs, warnings = collector.collect(snapshot)
+			}
+			if !interactive {
+				for _, warning := range warnings {
+					log.Warn(warning)
+				}
+				printJobLogs(jobLogs)
+				// Rendering at the zero time leaves out the elapsed times of anything still running, so the output only differs when the state does.
+				state := snapshot.Render(time.Time{}, 0)
+				if state == previousState {
+					return
+				}
+				previousState = state
+				fmt.Print(snapshot.Render(time.Now(), 0))
+				return
+			}
+			// Anything else printed while the progress is shown would throw off the number of lines to move back up by, so it is printed after the progress has been cleared.
+			if renderedLines > 0 {
+				fmt.Printf("\x1b[%dA\x1b[J", renderedLines)
+			}
+			for _, warning := range warnings {
+				log.Warn(warning)
+			}
+			printJobLogs(jobLogs)
+			rendered := snapshot.Render(time.Now(), terminalWidth(os.Stdout))
+			renderedLines = strings.Count(rendered, "\n")
+			fmt.Print(rendered)
+		},
+	}
+	return watcher.Watch(ctx, workflowRun.ID)
+}
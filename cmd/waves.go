@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
+	"github.com/chrisgavin/gh-dispatch/internal/rollout"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func readRepositoryList() ([]string, error) {
+	repositories := []string{}
+	for _, repository := range rootFlags.repos {
+		if repository = strings.TrimSpace(repository); repository != "" {
+			repositories = append(repositories, repository)
+		}
+	}
+	if rootFlags.reposFile != "" {
+		rawList, err := os.ReadFile(rootFlags.reposFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read repositories file.")
+		}
+		repositories = append(repositories, rollout.ParseRepositoryList(string(rawList))...)
+	}
+	return repositories, nil
+}
+
+// dispatchWaves dispatches one workflow across many repositories, a wave at a time, stopping if too many runs in a wave fail.
+func dispatchWaves(ctx context.Context, workflowName string, inputs map[string]string) error {
+	repositories, err := readRepositoryList()
+	if err != nil {
+		return err
+	}
+	if len(repositories) == 0 {
+		log.Error("No repositories to dispatch the workflow on.")
+		return SilentErr
+	}
+
+	specs := []spec.Spec{}
+	for _, fullRepository := range repositories {
+		var parsedRepository repository.Repository
+		if rootFlags.hostname != "" {
+			parsedRepository, err = repository.ParseWithHost(fullRepository, rootFlags.hostname)
+		} else {
+			parsedRepository, err = repository.Parse(fullRepository)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Unable to parse repository %s.", fullRepository)
+		}
+		specs = append(specs, spec.Spec{
+			Repository:       parsedRepository,
+			Ref:              rootFlags.ref,
+			Workflow:         workflowName,
+			Inputs:           inputs,
+			CorrelationInput: rootFlags.correlationInput,
+		})
+	}
+
+	// All of the flags are validated before anything is resolved or waited for, so that mistakes are reported straight away.
+	// The waves share their specs with the list of all specs, so resolving the specs below also resolves those in the waves.
+	waves, err := rollout.Waves(specs, rootFlags.waves)
+	if err != nil {
+		return err
+	}
+	if len(waves) > 1 && rootFlags.noWatch {
+		log.Error("Dispatching in waves requires watching the runs, so --no-watch cannot be used with --waves.")
+		return SilentErr
+	}
+	deferral, err := parseDeferral(repository.Repository{})
+	if err != nil {
+		return err
+	}
+
+	if rootFlags.dryRun {
+		requests := []dispatcher.Request{}
+		for _, unresolvedSpec := range specs {
+			resolvedSpec, err := spec.Resolve(unresolvedSpec)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to resolve %s.", unresolvedSpec)
+			}
+			requests = append(requests, dispatcher.WorkflowDispatchRequest(resolvedSpec.Repository, resolvedSpec.Ref, resolvedSpec.Workflow, resolvedSpec.Inputs))
+		}
+		return printDryRun(requests)
+	}
+
+	// The policies are confirmed once for the whole rollout, before waiting, so that the waves can be dispatched unattended.
+	if err := confirmPolicies(specs); err != nil {
+		return err
+	}
+
+	if err := deferral.wait(ctx); err != nil {
+		return err
+	}
+
+	if rootFlags.requireGreen || rootFlags.waitGreen {
+		for index, unresolvedSpec := range specs {
+			specs[index], err = spec.Resolve(unresolvedSpec)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to resolve %s.", unresolvedSpec)
+			}
+			if err := requireGreen(specs[index].Repository, specs[index].Ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	results := []spec.Result{}
+	for index, wave := range waves {
+		log.Infof("Dispatching wave %d of %d to %s with a parallelism of %d...", index+1, len(waves), formatCount(len(wave), "repository"), rootFlags.waveParallelism)
+		waveResults := spec.DispatchAll(wave, rootFlags.waveParallelism, !rootFlags.noWatch)
+		results = append(results, waveResults...)
+		if rootFlags.noWatch {
+			continue
+		}
+		if failures := countFailures(waveResults); failures > rootFlags.maxWaveFailures {
+			log.Errorf("%s in wave %d did not succeed, which is more than the %d allowed. Stopping the rollout.", formatCount(failures, "run"), index+1, rootFlags.maxWaveFailures)
+			for _, remainingWave := range waves[index+1:] {
+				for _, skippedSpec := range remainingWave {
+					results = append(results, spec.Result{Spec: skippedSpec, Err: spec.ErrSkipped})
+				}
+			}
+			break
+		}
+	}
+
+	if err := printResults(results); err != nil {
+		return err
+	}
+	if rootFlags.noWatch {
+		for _, result := range results {
+			if result.Err != nil {
+				return SilentErr
+			}
+		}
+		return nil
+	}
+	if failures := countFailures(results); failures > 0 {
+		log.Errorf("%s of %d did not succeed.", formatCount(failures, "repository"), len(results))
+		return SilentErr
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package batch
+
+import (
+	"fmt"
+
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type Entry struct {
+	Repository string                 `yaml:"repository"`
+	Ref        string                 `yaml:"ref"`
+	Workflow   string                 `yaml:"workflow"`
+	Inputs     map[string]interface{} `yaml:"inputs"`
+}
+
+type Manifest struct {
+	Hostname    string  `yaml:"hostname"`
+	Parallelism int     `yaml:"parallelism"`
+	Entries     []Entry `yaml:"entries"`
+}
+
+func ReadManifest(rawManifest []byte) (*Manifest, error) {
+	manifest := Manifest{}
+	if err := yaml.Unmarshal(rawManifest, &manifest); err != nil {
+		return nil, errors.Wrap(err, "Unable to parse manifest as YAML.")
+	}
+	if len(manifest.Entries) == 0 {
+		return nil, errors.New("Manifest does not contain any entries.")
+	}
+	for index, entry := range manifest.Entries {
+		if entry.Repository == "" {
+			return nil, errors.Errorf("Manifest entry %d has no repository.", index+1)
+		}
+		if entry.Workflow == "" {
+			return nil, errors.Errorf("Manifest entry %d has no workflow.", index+1)
+		}
+	}
+	return &manifest, nil
+}
+
+// Spec converts the entry into a dispatch spec, using the given hostname if the repository does not specify one.
+func (entry Entry) Spec(hostname string) (spec.Spec, error) {
+	var entryRepository repository.Repository
+	var err error
+	if hostname != "" {
+		entryRepository, err = repository.ParseWithHost(entry.Repository, hostname)
+	} else {
+		entryRepository, err = repository.Parse(entry.Repository)
+	}
+	if err != nil {
+		return spec.Spec{}, errors.Wrapf(err, "Unable to parse repository %s.", entry.Repository)
+	}
+	inputs := map[string]string{}
+	for key, value := range entry.Inputs {
+		inputs[key] = fmt.Sprintf("%v", value)
+	}
+	return spec.Spec{
+		Repository: entryRepository,
+		Ref:        entry.Ref,
+		Workflow:   entry.Workflow,
+		Inputs:     inputs,
+	}, nil
+}
+
+// Specs converts the manifest entries into dispatch specs, using the given hostname for repositories without one.
+// The manifest's hostname is only used if no hostname is given, so that one from the command line takes precedence.
+func (manifest Manifest) Specs(hostname string) ([]spec.Spec, error) {
+	if hostname == "" {
+		hostname = manifest.Hostname
+	}
+	specs := []spec.Spec{}
+	for index, entry := range manifest.Entries {
+		entrySpec, err := entry.Spec(hostname)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid manifest entry %d.", index+1)
+		}
+		specs = append(specs, entrySpec)
+	}
+	return specs, nil
+}
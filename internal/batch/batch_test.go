@@ -0,0 +1,59 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadManifest(t *testing.T) {
+	const manifestContent = `
+parallelism: 3
+entries:
+  - repository: owner/first
+    ref: main
+    workflow: release.yml
+    inputs:
+      version: 1.2.3
+      dry_run: true
+  - repository: github.example.com/owner/second
+    workflow: Release
+`
+	manifest, err := ReadManifest([]byte(manifestContent))
+	require.NoError(t, err)
+	require.Equal(t, 3, manifest.Parallelism)
+	specs, err := manifest.Specs("")
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	require.Equal(t, "github.com", specs[0].Repository.Host)
+	require.Equal(t, "first", specs[0].Repository.Name)
+	require.Equal(t, "main", specs[0].Ref)
+	require.Equal(t, map[string]string{"version": "1.2.3", "dry_run": "true"}, specs[0].Inputs)
+	require.Equal(t, "github.example.com", specs[1].Repository.Host)
+	require.Equal(t, "Release", specs[1].Workflow)
+}
+
+func TestManifestSpecsHostname(t *testing.T) {
+	manifest, err := ReadManifest([]byte("hostname: github.example.com\nentries:\n  - repository: owner/first\n    workflow: release.yml\n  - repository: github.other.com/owner/second\n    workflow: release.yml\n"))
+	require.NoError(t, err)
+
+	specs, err := manifest.Specs("")
+	require.NoError(t, err)
+	require.Equal(t, "github.example.com", specs[0].Repository.Host)
+	require.Equal(t, "github.other.com", specs[1].Repository.Host)
+
+	specs, err = manifest.Specs("github.flag.com")
+	require.NoError(t, err)
+	require.Equal(t, "github.flag.com", specs[0].Repository.Host)
+	require.Equal(t, "github.other.com", specs[1].Repository.Host)
+}
+
+func TestReadManifestWithoutEntries(t *testing.T) {
+	_, err := ReadManifest([]byte("parallelism: 3\n"))
+	require.Error(t, err)
+}
+
+func TestReadManifestWithoutWorkflow(t *testing.T) {
+	_, err := ReadManifest([]byte("entries:\n  - repository: owner/repo\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package bisect
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+)
+
+type parent struct {
+	SHA string `json:"sha"`
+}
+
+type commit struct {
+	SHA     string   `json:"sha"`
+	Parents []parent `json:"parents"`
+}
+
+type comparison struct {
+	Status  string   `json:"status"`
+	Commits []commit `json:"commits"`
+}
+
+// firstParentHistory walks back from the bad commit to the good commit through the first parent of each commit, returning the commits after the good commit oldest first.
+// The comparison also contains the commits of any side branches merged in between, but these are left out, as a side branch that forked before a bug was introduced would be good even though it comes after the bug in the comparison. A bug introduced on a side branch is found at the commit that merged it.
+func firstParentHistory(commits []commit, good string, bad string) ([]string, error) {
+	commitsBySHA := map[string]commit{}
+	for _, commit := range commits {
+		commitsBySHA[commit.SHA] = commit
+	}
+	history := []string{}
+	for sha := bad; sha != good; {
+		current, ok := commitsBySHA[sha]
+		if !ok || len(current.Parents) == 0 {
+			return nil, errors.Errorf("The good commit %s is not on the first-parent history of the bad commit %s.", good, bad)
+		}
+		history = append(history, sha)
+		sha = current.Parents[0].SHA
+	}
+	slices.Reverse(history)
+	return history, nil
+}
+
+// ListCommits lists the commits on the first-parent history after the good commit up to and including the bad commit, oldest first.
+func ListCommits(repository repository.Repository, good string, bad string) ([]string, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []commit{}
+	for page := 1; ; page++ {
+		response := comparison{}
+		if err := client.Get(fmt.Sprintf("repos/%s/%s/compare/%s...%s?per_page=100&page=%d", repository.Owner, repository.Name, good, bad, page), &response); err != nil {
+			return nil, errors.Wrap(err, "Unable to list the commits between the good and bad commits.")
+		}
+		if response.Status != "ahead" {
+			return nil, errors.Errorf("The good commit %s is not an ancestor of the bad commit %s.", good, bad)
+		}
+		commits = append(commits, response.Commits...)
+		if len(response.Commits) < 100 {
+			return firstParentHistory(commits, good, bad)
+		}
+	}
+}
+
+// Bisection keeps track of which commits have been found to be good or bad, narrowing down the first bad commit.
+type Bisection struct {
+	commits []string
+	// lastGood is the index of the newest commit known to be good, where -1 is the good commit the bisection started from.
+	lastGood int
+	// firstBad is the index of the oldest commit known to be bad.
+	firstBad int
+}
+
+// New starts a bisection of the commits after a good commit, oldest first, where the last commit is known to be bad.
+func New(commits []string) *Bisection {
+	return &Bisection{
+		commits:  commits,
+		lastGood: -1,
+		firstBad: len(commits) - 1,
+	}
+}
+
+func (bisection *Bisection) Done() bool {
+	return bisection.firstBad-bisection.lastGood <= 1
+}
+
+// Next returns the commit to test next, halfway between the newest good commit and the oldest bad commit.
+func (bisection *Bisection) Next() string {
+	return bisection.commits[bisection.lastGood+(bisection.firstBad-bisection.lastGood)/2]
+}
+
+func (bisection *Bisection) Mark(sha string, good bool) {
+	for index, candidate := range bisection.commits {
+		if candidate != sha {
+			continue
+		}
+		if good && index > bisection.lastGood {
+			bisection.lastGood = index
+		} else if !good && index < bisection.firstBad {
+			bisection.firstBad = index
+		}
+	}
+}
+
+// Remaining estimates how many more commits have to be tested.
+func (bisection *Bisection) Remaining() int {
+	steps := 0
+	for candidates := bisection.firstBad - bisection.lastGood - 1; candidates > 0; candidates /= 2 {
+		steps++
+	}
+	return steps
+}
+
+func (bisection *Bisection) FirstBad() string {
+	return bisection.commits[bisection.firstBad]
+}
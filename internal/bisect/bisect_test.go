@@ -0,0 +1,85 @@
+package bisect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBisection(t *testing.T) {
+	commits := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	firstBad := 5
+	bisection := New(commits)
+	require.Equal(t, 3, bisection.Remaining())
+	tested := 0
+	for !bisection.Done() {
+		next := bisection.Next()
+		index := 0
+		for commits[index] != next {
+			index++
+		}
+		bisection.Mark(next, index < firstBad)
+		tested++
+	}
+	require.Equal(t, "f", bisection.FirstBad())
+	require.LessOrEqual(t, tested, 3)
+}
+
+func TestBisectionOfSingleCommit(t *testing.T) {
+	bisection := New([]string{"a"})
+	require.True(t, bisection.Done())
+	require.Equal(t, "a", bisection.FirstBad())
+	require.Equal(t, 0, bisection.Remaining())
+}
+
+func TestBisectionFirstCommitBad(t *testing.T) {
+	bisection := New([]string{"a", "b", "c"})
+	for !bisection.Done() {
+		bisection.Mark(bisection.Next(), false)
+	}
+	require.Equal(t, "a", bisection.FirstBad())
+}
+
+func testCommit(sha string, parents ...string) commit {
+	result := commit{SHA: sha}
+	for _, parentSHA := range parents {
+		result.Parents = append(result.Parents, parent{SHA: parentSHA})
+	}
+	return result
+}
+
+func TestFirstParentHistoryOfLinearHistory(t *testing.T) {
+	commits := []commit{testCommit("b", "a"), testCommit("c", "b"), testCommit("d", "c")}
+	history, err := firstParentHistory(commits, "a", "d")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "d"}, history)
+}
+
+func TestFirstParentHistorySkipsMergedBranches(t *testing.T) {
+	// The side branch x-y forked from a before the bug was introduced in b, and was merged in m. The comparison lists it in between the commits of the main line.
+	commits := []commit{
+		testCommit("x", "a"),
+		testCommit("b", "a"),
+		testCommit("y", "x"),
+		testCommit("c", "b"),
+		testCommit("m", "c", "y"),
+		testCommit("d", "m"),
+	}
+	history, err := firstParentHistory(commits, "a", "d")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "m", "d"}, history)
+
+	// Only the commits without the bug are good, which on the side branch is all of them.
+	bisection := New(history)
+	for !bisection.Done() {
+		next := bisection.Next()
+		bisection.Mark(next, next == "x" || next == "y")
+	}
+	require.Equal(t, "b", bisection.FirstBad())
+}
+
+func TestFirstParentHistoryGoodOnSideBranch(t *testing.T) {
+	commits := []commit{testCommit("b", "a"), testCommit("x", "a"), testCommit("m", "b", "x")}
+	_, err := firstParentHistory(commits, "x", "m")
+	require.Error(t, err)
+}
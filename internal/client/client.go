@@ -1,19 +1,47 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"io"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/pkg/errors"
+	logrus "github.com/sirupsen/logrus"
 )
 
+// secondaryRateLimitWait is how long to wait after hitting a secondary rate limit that does not say when to retry, as GitHub recommends waiting at least a minute.
+const secondaryRateLimitWait = time.Minute
+
 func NewClient(host string) (*api.RESTClient, error) {
+	return newClient(host, checkRetry)
+}
+
+// NewDispatchClient creates a client for requests that must not be sent twice, such as dispatches.
+// It only retries requests that were rejected by a rate limit, since after any other failure GitHub may already have acted on the request.
+func NewDispatchClient(host string) (*api.RESTClient, error) {
+	return newClient(host, checkRateLimitRetry)
+}
+
+func newRetryableHTTPClient(retryPolicy retryablehttp.CheckRetry) *retryablehttp.Client {
 	retryableHTTPClient := retryablehttp.NewClient()
 	retryableHTTPClient.RetryMax = 5
 	retryableHTTPClient.Logger = log.New(io.Discard, "", log.LstdFlags)
-	retryableRoundTripper := retryablehttp.RoundTripper{Client: retryableHTTPClient}
+	retryableHTTPClient.CheckRetry = retryPolicy
+	retryableHTTPClient.Backoff = backoff
+	// Once the retries run out, hand back the last response rather than a generic error, so that callers can see it was rejected rather than lost.
+	retryableHTTPClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	return retryableHTTPClient
+}
+
+func newClient(host string, retryPolicy retryablehttp.CheckRetry) (*api.RESTClient, error) {
+	retryableRoundTripper := retryablehttp.RoundTripper{Client: newRetryableHTTPClient(retryPolicy)}
 
 	client, err := api.NewRESTClient(api.ClientOptions{Host: host, Transport: &retryableRoundTripper})
 	if err != nil {
@@ -21,3 +49,88 @@ func NewClient(host string) (*api.RESTClient, error) {
 	}
 	return client, nil
 }
+
+// checkRetry retries requests that were rejected by a rate limit as well as those the default policy retries.
+func checkRetry(ctx context.Context, response *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if markRateLimit(response) {
+		return true, nil
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, response, err)
+}
+
+func checkRateLimitRetry(ctx context.Context, response *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	return markRateLimit(response), nil
+}
+
+// rateLimitWaitHeader carries the wait worked out by the retry policy through to backoff on the response itself.
+// retryablehttp drains the body of the response in between the two, and a secondary rate limit can only be recognised from the body, so it has to be detected by the retry policy.
+const rateLimitWaitHeader = "X-Gh-Dispatch-Rate-Limit-Wait"
+
+// markRateLimit checks whether the response was rejected by a rate limit and, if so, records how long to wait on the response for backoff to find.
+func markRateLimit(response *http.Response) bool {
+	wait, limited := rateLimitWait(response, time.Now())
+	if limited {
+		if response.Header == nil {
+			response.Header = http.Header{}
+		}
+		response.Header.Set(rateLimitWaitHeader, wait.String())
+	}
+	return limited
+}
+
+// backoff waits until a rate limit resets, or falls back to exponential backoff for other failures.
+func backoff(minimum time.Duration, maximum time.Duration, attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if wait, err := time.ParseDuration(response.Header.Get(rateLimitWaitHeader)); err == nil {
+			logrus.Warnf("Rate limited, resuming in %s.", wait.Round(time.Second))
+			return wait
+		}
+	}
+	return retryablehttp.DefaultBackoff(minimum, maximum, attempt, response)
+}
+
+// rateLimitWait checks whether the response was rejected by a primary or secondary rate limit and, if so, how long to wait before retrying.
+func rateLimitWait(response *http.Response, now time.Time) (time.Duration, bool) {
+	if response == nil || (response.StatusCode != http.StatusForbidden && response.StatusCode != http.StatusTooManyRequests) {
+		return 0, false
+	}
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Unix(reset, 0).Sub(now) + time.Second
+			if wait < time.Second {
+				wait = time.Second
+			}
+			return wait, true
+		}
+	}
+	if response.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(response) {
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+// isSecondaryRateLimit looks for GitHub's secondary rate limit message in the body of a 403, leaving the body readable for whoever handles the response.
+func isSecondaryRateLimit(response *http.Response) bool {
+	if response.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, 64*1024))
+	_ = response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
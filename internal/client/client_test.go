@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/require"
+)
+
+func response(statusCode int, headers map[string]string, body string) *http.Response {
+	header := http.Header{}
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	return &http.Response{StatusCode: statusCode, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+type testResponse struct {
+	statusCode int
+	headers    map[string]string
+	body       string
+}
+
+// sendThroughClient sends a request through a client configured like the real ones to a server that gives each of the responses in turn, repeating the last one.
+// It returns the waits that backoff chose between attempts, without actually waiting for them, along with the final response.
+func sendThroughClient(t *testing.T, retryPolicy retryablehttp.CheckRetry, responses []testResponse) ([]time.Duration, *http.Response) {
+	attempt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		current := responses[min(attempt, len(responses)-1)]
+		attempt++
+		for key, value := range current.headers {
+			writer.Header().Set(key, value)
+		}
+		writer.WriteHeader(current.statusCode)
+		_, _ = writer.Write([]byte(current.body))
+	}))
+	t.Cleanup(server.Close)
+
+	waits := []time.Duration{}
+	retryableHTTPClient := newRetryableHTTPClient(retryPolicy)
+	retryableHTTPClient.Backoff = func(minimum time.Duration, maximum time.Duration, attempt int, response *http.Response) time.Duration {
+		waits = append(waits, backoff(minimum, maximum, attempt, response))
+		return time.Millisecond
+	}
+	finalResponse, err := retryableHTTPClient.Get(server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = finalResponse.Body.Close() })
+	return waits, finalResponse
+}
+
+func TestBackoffSecondaryLimit(t *testing.T) {
+	waits, finalResponse := sendThroughClient(t, checkRetry, []testResponse{
+		{statusCode: 403, body: `{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`},
+		{statusCode: 200},
+	})
+	require.Equal(t, []time.Duration{secondaryRateLimitWait}, waits)
+	require.Equal(t, 200, finalResponse.StatusCode)
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	waits, _ := sendThroughClient(t, checkRetry, []testResponse{
+		{statusCode: 429, headers: map[string]string{"Retry-After": "43"}},
+		{statusCode: 200},
+	})
+	require.Equal(t, []time.Duration{43 * time.Second}, waits)
+}
+
+func TestBackoffPrimaryLimit(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Unix()+30, 10)
+	waits, _ := sendThroughClient(t, checkRetry, []testResponse{
+		{statusCode: 403, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}},
+		{statusCode: 200},
+	})
+	require.Len(t, waits, 1)
+	require.InDelta(t, 31*time.Second, waits[0], float64(2*time.Second))
+}
+
+func TestBackoffOtherErrors(t *testing.T) {
+	waits, finalResponse := sendThroughClient(t, checkRetry, []testResponse{
+		{statusCode: 403, headers: map[string]string{"X-RateLimit-Remaining": "4000"}, body: `{"message": "Resource not accessible by integration"}`},
+	})
+	require.Empty(t, waits)
+	require.Equal(t, 403, finalResponse.StatusCode)
+
+	waits, _ = sendThroughClient(t, checkRetry, []testResponse{{statusCode: 502}, {statusCode: 200}})
+	require.Equal(t, []time.Duration{time.Second}, waits)
+}
+
+func TestDispatchClientBacksOffOnSecondaryLimit(t *testing.T) {
+	waits, finalResponse := sendThroughClient(t, checkRateLimitRetry, []testResponse{
+		{statusCode: 403, body: `{"message": "You have exceeded a secondary rate limit."}`},
+		{statusCode: 204},
+	})
+	require.Equal(t, []time.Duration{secondaryRateLimitWait}, waits)
+	require.Equal(t, 204, finalResponse.StatusCode)
+}
+
+func TestDispatchClientReturnsRejectionWhenRetriesRunOut(t *testing.T) {
+	waits, finalResponse := sendThroughClient(t, checkRateLimitRetry, []testResponse{
+		{statusCode: 429, headers: map[string]string{"Retry-After": "1"}, body: `{"message": "API rate limit exceeded."}`},
+	})
+	require.Len(t, waits, 5)
+	require.Equal(t, 429, finalResponse.StatusCode)
+	body, err := io.ReadAll(finalResponse.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "API rate limit exceeded.")
+}
+
+func TestDispatchClientOnlyRetriesRateLimits(t *testing.T) {
+	retry, err := checkRateLimitRetry(context.Background(), response(502, nil, ""), nil)
+	require.NoError(t, err)
+	require.False(t, retry)
+	retry, err = checkRateLimitRetry(context.Background(), nil, errors.New("connection reset by peer"))
+	require.NoError(t, err)
+	require.False(t, retry)
+	retry, err = checkRateLimitRetry(context.Background(), response(429, nil, ""), nil)
+	require.NoError(t, err)
+	require.True(t, retry)
+}
@@ -0,0 +1,194 @@
+package commit_status
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type State string
+
+const (
+	Green   State = "green"
+	Pending State = "pending"
+	Failed  State = "failed"
+	// NoChecks is the state of a commit that no statuses or checks have been reported on, which is usually because CI has not picked it up yet.
+	NoChecks State = "no_checks"
+)
+
+// noChecksGracePeriod is how long WaitForGreen waits for the first status or check to be reported on a commit before assuming that the repository has no CI.
+const noChecksGracePeriod = 2 * time.Minute
+
+type Status struct {
+	SHA     string
+	State   State
+	Pending []string
+	Failed  []string
+}
+
+type commit struct {
+	SHA string `json:"sha"`
+}
+
+type commitStatus struct {
+	Context string `json:"context"`
+	State   string `json:"state"`
+}
+
+type combinedStatus struct {
+	Statuses []commitStatus `json:"statuses"`
+}
+
+type checkRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+type checkRuns struct {
+	CheckRuns []checkRun `json:"check_runs"`
+}
+
+var failedCheckConclusions = map[string]bool{
+	"failure":         true,
+	"cancelled":       true,
+	"timed_out":       true,
+	"action_required": true,
+	"startup_failure": true,
+}
+
+// GetHeadSHA returns the commit a fully qualified ref currently points at.
+func GetHeadSHA(repository repository.Repository, reference string) (string, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return "", err
+	}
+	headCommit := commit{}
+	if err := client.Get(fmt.Sprintf("repos/%s/%s/commits/%s", repository.Owner, repository.Name, strings.TrimPrefix(reference, "refs/")), &headCommit); err != nil {
+		return "", errors.Wrapf(err, "Unable to get head commit of %s.", reference)
+	}
+	return headCommit.SHA, nil
+}
+
+// combine combines the commit statuses and check runs of a commit into a single state.
+func combine(sha string, statuses []commitStatus, checks []checkRun) Status {
+	status := Status{SHA: sha}
+	for _, commitStatus := range statuses {
+		switch commitStatus.State {
+		case "success":
+		case "pending":
+			status.Pending = append(status.Pending, commitStatus.Context)
+		default:
+			status.Failed = append(status.Failed, commitStatus.Context)
+		}
+	}
+	for _, check := range checks {
+		if check.Status != "completed" {
+			status.Pending = append(status.Pending, check.Name)
+		} else if failedCheckConclusions[check.Conclusion] {
+			status.Failed = append(status.Failed, check.Name)
+		}
+	}
+	sort.Strings(status.Pending)
+	sort.Strings(status.Failed)
+
+	switch {
+	case len(status.Failed) > 0:
+		status.State = Failed
+	case len(status.Pending) > 0:
+		status.State = Pending
+	case len(statuses) == 0 && len(checks) == 0:
+		status.State = NoChecks
+	default:
+		status.State = Green
+	}
+	return status
+}
+
+// GetStatus combines the commit statuses and check runs of a commit into a single state.
+func GetStatus(repository repository.Repository, sha string) (*Status, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := listStatuses(client, repository, sha)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := listCheckRuns(client, repository, sha)
+	if err != nil {
+		return nil, err
+	}
+	status := combine(sha, statuses, checks)
+	return &status, nil
+}
+
+func listStatuses(client *api.RESTClient, repository repository.Repository, sha string) ([]commitStatus, error) {
+	statuses := []commitStatus{}
+	for page := 1; ; page++ {
+		response := combinedStatus{}
+		if err := client.Get(fmt.Sprintf("repos/%s/%s/commits/%s/status?per_page=100&page=%d", repository.Owner, repository.Name, sha, page), &response); err != nil {
+			return nil, errors.Wrap(err, "Unable to get commit status.")
+		}
+		statuses = append(statuses, response.Statuses...)
+		if len(response.Statuses) < 100 {
+			return statuses, nil
+		}
+	}
+}
+
+func listCheckRuns(client *api.RESTClient, repository repository.Repository, sha string) ([]checkRun, error) {
+	checks := []checkRun{}
+	for page := 1; ; page++ {
+		response := checkRuns{}
+		if err := client.Get(fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?per_page=100&page=%d", repository.Owner, repository.Name, sha, page), &response); err != nil {
+			return nil, errors.Wrap(err, "Unable to list check runs.")
+		}
+		checks = append(checks, response.CheckRuns...)
+		if len(response.CheckRuns) < 100 {
+			return checks, nil
+		}
+	}
+}
+
+// WaitForGreen polls the commit until all of its statuses and checks have passed, returning early as soon as any of them fail.
+// A commit that has just been pushed may not have any checks yet, so if none have been reported they are waited for too. If none are reported within a grace period then the repository is assumed not to have any CI and the commit is treated as green.
+func WaitForGreen(repository repository.Repository, sha string, timeout time.Duration) (*Status, error) {
+	start := time.Now()
+	deadline := start.Add(timeout)
+	for {
+		status, err := GetStatus(repository, sha)
+		if err != nil {
+			return nil, err
+		}
+		if status.State == NoChecks {
+			if time.Since(start) >= noChecksGracePeriod {
+				log.Warnf("No statuses or checks were reported on %s within %s, so treating it as green.", sha, noChecksGracePeriod)
+				status.State = Green
+				return status, nil
+			}
+			if time.Now().After(deadline) {
+				return nil, errors.Errorf("No statuses or checks were reported on %s within %s.", sha, timeout)
+			}
+			log.Infof("Waiting for statuses or checks to be reported on %s.", sha)
+			time.Sleep(15 * time.Second)
+			continue
+		}
+		if status.State != Pending {
+			return status, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("Checks on %s did not complete within %s. Still waiting for %s.", sha, timeout, strings.Join(status.Pending, ", "))
+		}
+		log.Infof("Waiting for %d pending checks on %s: %s", len(status.Pending), sha, strings.Join(status.Pending, ", "))
+		time.Sleep(15 * time.Second)
+	}
+}
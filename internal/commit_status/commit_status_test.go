@@ -0,0 +1,54 @@
+package commit_status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []commitStatus
+		checks   []checkRun
+		expected Status
+	}{
+		{
+			name:     "no statuses or checks",
+			expected: Status{SHA: "abc", State: NoChecks},
+		},
+		{
+			name:     "all passed",
+			statuses: []commitStatus{{Context: "ci/jenkins", State: "success"}},
+			checks:   []checkRun{{Name: "build", Status: "completed", Conclusion: "success"}, {Name: "lint", Status: "completed", Conclusion: "skipped"}},
+			expected: Status{SHA: "abc", State: Green},
+		},
+		{
+			name:     "only checks that do not count as failures",
+			checks:   []checkRun{{Name: "optional", Status: "completed", Conclusion: "neutral"}},
+			expected: Status{SHA: "abc", State: Green},
+		},
+		{
+			name:     "pending status and check",
+			statuses: []commitStatus{{Context: "ci/jenkins", State: "pending"}},
+			checks:   []checkRun{{Name: "build", Status: "in_progress"}, {Name: "test", Status: "queued"}, {Name: "lint", Status: "completed", Conclusion: "success"}},
+			expected: Status{SHA: "abc", State: Pending, Pending: []string{"build", "ci/jenkins", "test"}},
+		},
+		{
+			name:     "failures take precedence over pending",
+			statuses: []commitStatus{{Context: "ci/jenkins", State: "error"}, {Context: "deploy/preview", State: "failure"}},
+			checks:   []checkRun{{Name: "build", Status: "in_progress"}, {Name: "test", Status: "completed", Conclusion: "timed_out"}},
+			expected: Status{SHA: "abc", State: Failed, Pending: []string{"build"}, Failed: []string{"ci/jenkins", "deploy/preview", "test"}},
+		},
+		{
+			name:     "checks needing action are failures",
+			checks:   []checkRun{{Name: "security", Status: "completed", Conclusion: "action_required"}},
+			expected: Status{SHA: "abc", State: Failed, Failed: []string{"security"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, combine("abc", test.statuses, test.checks))
+		})
+	}
+}
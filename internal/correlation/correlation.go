@@ -0,0 +1,40 @@
+package correlation
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/pkg/errors"
+)
+
+// inputNames are the input names that are recognised as correlation inputs without having to be configured explicitly.
+var inputNames = []string{"dispatch_id", "correlation_id", "distinct_id"}
+
+// DetectInput returns the name of the input that a correlation ID should be passed through, or an empty string if the workflow does not have one.
+func DetectInput(workflowData workflow.Workflow, configuredName string) (string, error) {
+	if configuredName != "" {
+		for _, input := range workflowData.Inputs {
+			if input.Name == configuredName {
+				return configuredName, nil
+			}
+		}
+		return "", errors.Errorf("Correlation input %s not accepted by workflow.", configuredName)
+	}
+	for _, name := range inputNames {
+		for _, input := range workflowData.Inputs {
+			if input.Name == name {
+				return name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func NewID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.Wrap(err, "Unable to generate correlation ID.")
+	}
+	return hex.EncodeToString(bytes), nil
+}
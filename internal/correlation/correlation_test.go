@@ -0,0 +1,30 @@
+package correlation
+
+import (
+	"testing"
+
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectInput(t *testing.T) {
+	workflowData := workflow.Workflow{Inputs: []workflow.Input{{Name: "version"}, {Name: "dispatch_id"}}}
+	name, err := DetectInput(workflowData, "")
+	require.NoError(t, err)
+	require.Equal(t, "dispatch_id", name)
+}
+
+func TestDetectInputConfigured(t *testing.T) {
+	workflowData := workflow.Workflow{Inputs: []workflow.Input{{Name: "version"}, {Name: "dispatch_id"}}}
+	name, err := DetectInput(workflowData, "version")
+	require.NoError(t, err)
+	require.Equal(t, "version", name)
+	_, err = DetectInput(workflowData, "missing")
+	require.Error(t, err)
+}
+
+func TestDetectInputAbsent(t *testing.T) {
+	name, err := DetectInput(workflow.Workflow{Inputs: []workflow.Input{{Name: "version"}}}, "")
+	require.NoError(t, err)
+	require.Empty(t, name)
+}
@@ -0,0 +1,169 @@
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed five field cron expression, matching the syntax used by `schedule:` triggers in GitHub Actions.
+type Schedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// If both the day of the month and the day of the week are restricted then a time matching either of them matches, as in standard cron.
+	daysOfMonthRestricted bool
+	daysOfWeekRestricted  bool
+}
+
+type field struct {
+	minimum int
+	maximum int
+	names   map[string]int
+}
+
+var monthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+var dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+var fields = []field{
+	{minimum: 0, maximum: 59},
+	{minimum: 0, maximum: 23},
+	{minimum: 1, maximum: 31},
+	{minimum: 1, maximum: 12, names: monthNames},
+	{minimum: 0, maximum: 7, names: dayNames},
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a five field cron expression or one of the `@daily` style macros. Day of week 7 is treated as Sunday.
+func Parse(expression string) (*Schedule, error) {
+	if expanded, ok := macros[strings.ToLower(strings.TrimSpace(expression))]; ok {
+		expression = expanded
+	}
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf("Cron expression %s must have %d fields.", expression, len(fields))
+	}
+
+	values := []map[int]bool{}
+	for index, part := range parts {
+		parsed, err := fields[index].parse(part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid cron expression %s.", expression)
+		}
+		values = append(values, parsed)
+	}
+	// Both 0 and 7 mean Sunday.
+	if values[4][7] {
+		values[4][0] = true
+	}
+	// As in cron itself, a day field starting with * counts as unrestricted even if it has a step, so that it narrows down the other day field rather than adding to it.
+	return &Schedule{
+		minutes:               values[0],
+		hours:                 values[1],
+		daysOfMonth:           values[2],
+		months:                values[3],
+		daysOfWeek:            values[4],
+		daysOfMonthRestricted: !strings.HasPrefix(parts[2], "*"),
+		daysOfWeekRestricted:  !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func (field field) parse(expression string) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, item := range strings.Split(expression, ",") {
+		rangeExpression, stepExpression, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpression)
+			if err != nil || step < 1 {
+				return nil, errors.Errorf("Invalid step %s.", stepExpression)
+			}
+		}
+
+		start, end := field.minimum, field.maximum
+		if rangeExpression != "*" {
+			startExpression, endExpression, isRange := strings.Cut(rangeExpression, "-")
+			var err error
+			start, err = field.value(startExpression)
+			if err != nil {
+				return nil, err
+			}
+			end = start
+			if isRange {
+				end, err = field.value(endExpression)
+				if err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				end = field.maximum
+			}
+			if end < start {
+				return nil, errors.Errorf("Invalid range %s.", rangeExpression)
+			}
+		}
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+func (field field) value(expression string) (int, error) {
+	if value, ok := field.names[strings.ToLower(expression)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(expression)
+	if err != nil || value < field.minimum || value > field.maximum {
+		return 0, errors.Errorf("Value %s must be between %d and %d.", expression, field.minimum, field.maximum)
+	}
+	return value, nil
+}
+
+// Matches checks whether the minute containing the given time matches the schedule.
+func (schedule Schedule) Matches(t time.Time) bool {
+	return schedule.minutes[t.Minute()] && schedule.hours[t.Hour()] && schedule.months[int(t.Month())] && schedule.dayMatches(t)
+}
+
+func (schedule Schedule) dayMatches(t time.Time) bool {
+	dayOfMonthMatches := schedule.daysOfMonth[t.Day()]
+	dayOfWeekMatches := schedule.daysOfWeek[int(t.Weekday())]
+	if schedule.daysOfMonthRestricted && schedule.daysOfWeekRestricted {
+		return dayOfMonthMatches || dayOfWeekMatches
+	}
+	return dayOfMonthMatches && dayOfWeekMatches
+}
+
+// Next returns the first time strictly after the given time that matches the schedule, or the zero time if there is none within five years.
+func (schedule Schedule) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		switch {
+		case !schedule.months[int(next.Month())]:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !schedule.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !schedule.hours[next.Hour()]:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !schedule.minutes[next.Minute()]:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseTestSchedule(t *testing.T, expression string) *Schedule {
+	schedule, err := Parse(expression)
+	require.NoError(t, err)
+	return schedule
+}
+
+func TestNextDaily(t *testing.T) {
+	schedule := parseTestSchedule(t, "30 3 * * *")
+	require.Equal(t, time.Date(2026, 10, 18, 3, 30, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2026, 10, 19, 3, 30, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 18, 3, 30, 0, 0, time.UTC)))
+}
+
+func TestNextWithSteps(t *testing.T) {
+	schedule := parseTestSchedule(t, "*/15 9-17 * * mon-fri")
+	// 2026-10-17 is a Saturday.
+	require.Equal(t, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2026, 10, 19, 9, 15, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestNextWithDayOfMonthOrDayOfWeek(t *testing.T) {
+	schedule := parseTestSchedule(t, "0 0 1 * 0")
+	// 2026-10-18 is a Sunday, which matches even though it is not the first of the month.
+	require.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 25, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestNextWithSteppedDayOfWeek(t *testing.T) {
+	schedule := parseTestSchedule(t, "0 0 1 * */2")
+	// The day of week starts with *, so it narrows down the first of the month to those that are a Sunday, Tuesday, Thursday or Saturday.
+	require.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNextWithMacro(t *testing.T) {
+	schedule := parseTestSchedule(t, "@monthly")
+	require.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNextSundayAsSeven(t *testing.T) {
+	schedule := parseTestSchedule(t, "0 12 * * 7")
+	require.Equal(t, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), schedule.Next(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expression := range []string{"* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		_, err := Parse(expression)
+		require.Error(t, err, expression)
+	}
+}
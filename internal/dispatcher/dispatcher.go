@@ -4,31 +4,138 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 	"github.com/cli/go-gh/v2/pkg/repository"
 	"github.com/pkg/errors"
 )
 
-func DispatchWorkflow(repository repository.Repository, reference string, workflowName string, inputs map[string]string) error {
-	client, err := client.NewClient(repository.Host)
+const returnRunDetailsParameter = "return_run_details"
+
+type RunDetails struct {
+	WorkflowRunID int64  `json:"workflow_run_id"`
+	RunURL        string `json:"run_url"`
+	HTMLURL       string `json:"html_url"`
+}
+
+// PossiblyDispatchedError is returned when a dispatch failed in a way that does not show whether GitHub acted on it, such as a lost connection or a server error.
+// Sending the dispatch again could run the workflow twice, so the caller should look for the run instead.
+type PossiblyDispatchedError struct {
+	Err error
+}
+
+func (err *PossiblyDispatchedError) Error() string {
+	return fmt.Sprintf("The dispatch failed, but may still have been received: %s", err.Err)
+}
+
+func (err *PossiblyDispatchedError) Unwrap() error {
+	return err.Err
+}
+
+// classifyError marks failures other than the request being rejected as possibly dispatched.
+func classifyError(err error, message string) error {
+	if httpError, ok := err.(*api.HTTPError); ok && httpError.StatusCode < 500 {
+		return errors.Wrap(err, message)
+	}
+	return &PossiblyDispatchedError{Err: err}
+}
+
+// Request is an API request that a dispatch would make, without the run details parameter, so it can be shown to the user instead of being sent.
+type Request struct {
+	Method string
+	Host   string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// URL returns the full URL of the request on the host's REST API.
+func (request Request) URL() string {
+	host := auth.NormalizeHostname(request.Host)
+	if auth.IsEnterprise(host) {
+		return fmt.Sprintf("https://%s/api/v3/%s", host, request.Path)
+	}
+	return fmt.Sprintf("https://api.%s/%s", host, request.Path)
+}
+
+func WorkflowDispatchRequest(repository repository.Repository, reference string, workflowName string, inputs map[string]string) Request {
+	return Request{
+		Method: http.MethodPost,
+		Host:   repository.Host,
+		Path:   fmt.Sprintf("repos/%s/%s/actions/workflows/%s/dispatches", repository.Owner, repository.Name, workflowName),
+		Body: map[string]interface{}{
+			"ref":    reference,
+			"inputs": inputs,
+		},
+	}
+}
+
+// DispatchWorkflow dispatches the workflow and returns the details of the run it created.
+// The run details are nil if the host does not support returning them, in which case the run has to be located some other way.
+// The request is never retried unless it was rejected by a rate limit. If it fails in a way that does not show whether it was received then a *PossiblyDispatchedError is returned.
+func DispatchWorkflow(repository repository.Repository, reference string, workflowName string, inputs map[string]string) (*RunDetails, error) {
+	client, err := client.NewDispatchClient(repository.Host)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	body := map[string]interface{}{
-		"ref":    reference,
-		"inputs": inputs,
+	request := WorkflowDispatchRequest(repository, reference, workflowName, inputs)
+	runDetails := RunDetails{}
+	err = postDispatch(client, request, true, &runDetails)
+	if httpError, ok := err.(*api.HTTPError); ok && httpError.StatusCode == 422 && strings.Contains(httpError.Message, returnRunDetailsParameter) {
+		// Older GitHub Enterprise Server versions reject the parameter outright rather than ignoring it, and since the request was rejected it is safe to send it again without the parameter.
+		err = postDispatch(client, request, false, &runDetails)
+	}
+	if err != nil {
+		return nil, classifyError(err, "Unable to dispatch workflow.")
+	}
+
+	if runDetails.WorkflowRunID == 0 {
+		return nil, nil
+	}
+	return &runDetails, nil
+}
+
+func postDispatch(client *api.RESTClient, request Request, returnRunDetails bool, response *RunDetails) error {
+	body := map[string]interface{}{}
+	for key, value := range request.Body {
+		body[key] = value
+	}
+	if returnRunDetails {
+		body[returnRunDetailsParameter] = true
 	}
 	encodedBody, err := json.Marshal(body)
 	if err != nil {
 		return errors.Wrap(err, "Unable to marshal workflow dispatch body.")
 	}
 
-	err = client.Post(fmt.Sprintf("repos/%s/%s/actions/workflows/%s/dispatches", repository.Owner, repository.Name, workflowName), bytes.NewReader(encodedBody), nil)
+	return client.Post(request.Path, bytes.NewReader(encodedBody), response)
+}
+
+// DispatchRepositoryEvent sends a repository_dispatch event, which triggers every workflow on the default branch that listens for the event type.
+// Like DispatchWorkflow, the request is not retried and a *PossiblyDispatchedError is returned if it is unclear whether it was received.
+func DispatchRepositoryEvent(repository repository.Repository, eventType string, clientPayload json.RawMessage) error {
+	client, err := client.NewDispatchClient(repository.Host)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"event_type": eventType,
+	}
+	if len(clientPayload) > 0 {
+		body["client_payload"] = clientPayload
+	}
+	encodedBody, err := json.Marshal(body)
 	if err != nil {
-		return errors.Wrap(err, "Unable to dispatch workflow.")
+		return errors.Wrap(err, "Unable to marshal repository dispatch body.")
 	}
 
+	if err := client.Post(fmt.Sprintf("repos/%s/%s/dispatches", repository.Owner, repository.Name), bytes.NewReader(encodedBody), nil); err != nil {
+		return classifyError(err, "Unable to send repository dispatch event.")
+	}
 	return nil
 }
@@ -0,0 +1,26 @@
+package dispatcher
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	var possiblyDispatched *PossiblyDispatchedError
+
+	rejected := classifyError(&api.HTTPError{StatusCode: 422, Message: "Unexpected inputs provided"}, "Unable to dispatch workflow.")
+	require.False(t, errors.As(rejected, &possiblyDispatched))
+
+	rateLimited := classifyError(&api.HTTPError{StatusCode: 429, Message: "API rate limit exceeded."}, "Unable to dispatch workflow.")
+	require.False(t, errors.As(rateLimited, &possiblyDispatched))
+
+	serverError := classifyError(&api.HTTPError{StatusCode: 502}, "Unable to dispatch workflow.")
+	require.True(t, errors.As(serverError, &possiblyDispatched))
+
+	connectionError := classifyError(&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, "Unable to dispatch workflow.")
+	require.True(t, errors.As(connectionError, &possiblyDispatched))
+}
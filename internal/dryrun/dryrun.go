@@ -0,0 +1,101 @@
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
+	"github.com/pkg/errors"
+)
+
+const (
+	TextFormat  = "text"
+	JSONFormat  = "json"
+	CurlFormat  = "curl"
+	GHAPIFormat = "gh-api"
+)
+
+var Formats = []string{TextFormat, JSONFormat, CurlFormat, GHAPIFormat}
+
+type jsonRequest struct {
+	Method string                 `json:"method"`
+	URL    string                 `json:"url"`
+	Body   map[string]interface{} `json:"body"`
+}
+
+// Format renders a request that would have been made in the given format.
+func Format(request dispatcher.Request, format string) (string, error) {
+	switch format {
+	case TextFormat:
+		return formatText(request), nil
+	case JSONFormat:
+		encoded, err := json.MarshalIndent(jsonRequest{Method: request.Method, URL: request.URL(), Body: request.Body}, "", "  ")
+		if err != nil {
+			return "", errors.Wrap(err, "Unable to marshal request.")
+		}
+		return string(encoded), nil
+	case CurlFormat:
+		encodedBody, err := json.Marshal(request.Body)
+		if err != nil {
+			return "", errors.Wrap(err, "Unable to marshal request body.")
+		}
+		return fmt.Sprintf("curl --request %s --header 'Accept: application/vnd.github+json' --header \"Authorization: Bearer $GH_TOKEN\" %s --data %s", request.Method, shellQuote(request.URL()), shellQuote(string(encodedBody))), nil
+	case GHAPIFormat:
+		arguments := []string{"gh", "api", "--method", request.Method}
+		if request.Host != "github.com" {
+			arguments = append(arguments, "--hostname", shellQuote(request.Host))
+		}
+		arguments = append(arguments, shellQuote(request.Path))
+		for _, field := range flattenFields("", request.Body) {
+			arguments = append(arguments, "--raw-field", shellQuote(field))
+		}
+		return strings.Join(arguments, " "), nil
+	default:
+		return "", errors.Errorf("Unknown output format %s. Valid formats are %s.", format, strings.Join(Formats, ", "))
+	}
+}
+
+func formatText(request dispatcher.Request) string {
+	lines := []string{
+		fmt.Sprintf("Would %s %s", request.Method, request.URL()),
+	}
+	for _, field := range flattenFields("", request.Body) {
+		lines = append(lines, "  "+field)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// flattenFields renders the body as sorted `key=value` fields, using the `key[subkey]=value` syntax understood by `gh api` for nested objects.
+func flattenFields(prefix string, body map[string]interface{}) []string {
+	keys := []string{}
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := []string{}
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = fmt.Sprintf("%s[%s]", prefix, key)
+		}
+		switch value := body[key].(type) {
+		case map[string]interface{}:
+			fields = append(fields, flattenFields(name, value)...)
+		case map[string]string:
+			nested := map[string]interface{}{}
+			for nestedKey, nestedValue := range value {
+				nested[nestedKey] = nestedValue
+			}
+			fields = append(fields, flattenFields(name, nested)...)
+		default:
+			fields = append(fields, fmt.Sprintf("%s=%v", name, value))
+		}
+	}
+	return fields
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,52 @@
+package dryrun
+
+import (
+	"testing"
+
+	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/require"
+)
+
+var testRequest = dispatcher.WorkflowDispatchRequest(
+	repository.Repository{Host: "github.com", Owner: "owner", Name: "repo"},
+	"refs/heads/main",
+	"release.yml",
+	map[string]string{"version": "1.2.3", "notes": "It's done."},
+)
+
+func TestFormatJSON(t *testing.T) {
+	output, err := Format(testRequest, JSONFormat)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"method": "POST",
+		"url": "https://api.github.com/repos/owner/repo/actions/workflows/release.yml/dispatches",
+		"body": {"ref": "refs/heads/main", "inputs": {"version": "1.2.3", "notes": "It's done."}}
+	}`, output)
+}
+
+func TestFormatCurl(t *testing.T) {
+	output, err := Format(testRequest, CurlFormat)
+	require.NoError(t, err)
+	require.Equal(t, `curl --request POST --header 'Accept: application/vnd.github+json' --header "Authorization: Bearer $GH_TOKEN" 'https://api.github.com/repos/owner/repo/actions/workflows/release.yml/dispatches' --data '{"inputs":{"notes":"It'\''s done.","version":"1.2.3"},"ref":"refs/heads/main"}'`, output)
+}
+
+func TestFormatGHAPI(t *testing.T) {
+	output, err := Format(testRequest, GHAPIFormat)
+	require.NoError(t, err)
+	require.Equal(t, `gh api --method POST 'repos/owner/repo/actions/workflows/release.yml/dispatches' --raw-field 'inputs[notes]=It'\''s done.' --raw-field 'inputs[version]=1.2.3' --raw-field 'ref=refs/heads/main'`, output)
+}
+
+func TestFormatGHAPIOnEnterprise(t *testing.T) {
+	enterpriseRequest := testRequest
+	enterpriseRequest.Host = "github.example.com"
+	output, err := Format(enterpriseRequest, GHAPIFormat)
+	require.NoError(t, err)
+	require.Contains(t, output, "--hostname 'github.example.com'")
+	require.Equal(t, "https://github.example.com/api/v3/repos/owner/repo/actions/workflows/release.yml/dispatches", enterpriseRequest.URL())
+}
+
+func TestFormatUnknown(t *testing.T) {
+	_, err := Format(testRequest, "yaml")
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package flakiness
+
+import (
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+)
+
+// Run is the jobs of one of the repeated runs, along with the correlation ID it was dispatched with, if it had one.
+type Run struct {
+	CorrelationID string
+	Jobs          []run.Job
+}
+
+type JobSummary struct {
+	Name   string
+	Passed int
+	Total  int
+	// FailedURLs link to the job in each run where it did not pass.
+	FailedURLs []string
+}
+
+func (summary JobSummary) PassRate() float64 {
+	if summary.Total == 0 {
+		return 0
+	}
+	return float64(summary.Passed) / float64(summary.Total)
+}
+
+// emptyBrackets are what is left of brackets that only held a correlation ID once it has been removed.
+var emptyBrackets = strings.NewReplacer("()", "", "[]", "", "{}", "")
+
+// stripCorrelationID removes the run's correlation ID from a job name, so that the same job has the same name in every run.
+// Workflows often put the ID in a job name so that the run can be found, which would otherwise make every job look different.
+func stripCorrelationID(name string, correlationID string) string {
+	if correlationID == "" || !strings.Contains(name, correlationID) {
+		return name
+	}
+	name = emptyBrackets.Replace(strings.ReplaceAll(name, correlationID, ""))
+	return strings.Trim(strings.Join(strings.Fields(name), " "), " -_:/")
+}
+
+// Summarize counts how often each job passed across the runs, in the order the jobs first appear.
+// Skipped and neutral jobs are not counted, since they neither passed nor failed.
+func Summarize(runs []Run) []JobSummary {
+	summaries := []JobSummary{}
+	indexes := map[string]int{}
+	for _, repeatedRun := range runs {
+		for _, job := range repeatedRun.Jobs {
+			if job.Conclusion == "skipped" || job.Conclusion == "neutral" {
+				continue
+			}
+			name := stripCorrelationID(job.Name, repeatedRun.CorrelationID)
+			index, ok := indexes[name]
+			if !ok {
+				index = len(summaries)
+				indexes[name] = index
+				summaries = append(summaries, JobSummary{Name: name})
+			}
+			summary := &summaries[index]
+			summary.Total++
+			if job.Conclusion == "success" {
+				summary.Passed++
+			} else {
+				summary.FailedURLs = append(summary.FailedURLs, job.HTMLURL)
+			}
+		}
+	}
+	return summaries
+}
@@ -0,0 +1,59 @@
+package flakiness
+
+import (
+	"testing"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	summaries := Summarize([]Run{
+		{Jobs: []run.Job{
+			{Name: "build", Conclusion: "success"},
+			{Name: "e2e", Conclusion: "failure", HTMLURL: "https://github.com/owner/repo/actions/runs/1/job/11"},
+		}},
+		{Jobs: []run.Job{
+			{Name: "build", Conclusion: "success"},
+			{Name: "e2e", Conclusion: "success"},
+			{Name: "deploy", Conclusion: "skipped"},
+		}},
+		{Jobs: []run.Job{
+			{Name: "build", Conclusion: "success"},
+			{Name: "e2e", Conclusion: "timed_out", HTMLURL: "https://github.com/owner/repo/actions/runs/3/job/33"},
+		}},
+	})
+	require.Equal(t, []JobSummary{
+		{Name: "build", Passed: 3, Total: 3},
+		{Name: "e2e", Passed: 1, Total: 3, FailedURLs: []string{"https://github.com/owner/repo/actions/runs/1/job/11", "https://github.com/owner/repo/actions/runs/3/job/33"}},
+	}, summaries)
+	require.InDelta(t, 1.0/3, summaries[1].PassRate(), 0.0001)
+}
+
+func TestSummarizeWithCorrelatedJobNames(t *testing.T) {
+	summaries := Summarize([]Run{
+		{CorrelationID: "0a1b", Jobs: []run.Job{
+			{Name: "build (0a1b)", Conclusion: "success"},
+			{Name: "test", Conclusion: "failure", HTMLURL: "https://github.com/owner/repo/actions/runs/1/job/11"},
+		}},
+		{CorrelationID: "2c3d", Jobs: []run.Job{
+			{Name: "build (2c3d)", Conclusion: "failure", HTMLURL: "https://github.com/owner/repo/actions/runs/2/job/21"},
+			{Name: "test", Conclusion: "success"},
+		}},
+		{CorrelationID: "4e5f", Jobs: []run.Job{
+			{Name: "build (4e5f)", Conclusion: "success"},
+			{Name: "test", Conclusion: "success"},
+		}},
+	})
+	require.Equal(t, []JobSummary{
+		{Name: "build", Passed: 2, Total: 3, FailedURLs: []string{"https://github.com/owner/repo/actions/runs/2/job/21"}},
+		{Name: "test", Passed: 2, Total: 3, FailedURLs: []string{"https://github.com/owner/repo/actions/runs/1/job/11"}},
+	}, summaries)
+}
+
+func TestStripCorrelationID(t *testing.T) {
+	require.Equal(t, "build", stripCorrelationID("build (0a1b)", "0a1b"))
+	require.Equal(t, "build", stripCorrelationID("build - 0a1b", "0a1b"))
+	require.Equal(t, "build on linux", stripCorrelationID("build [0a1b] on linux", "0a1b"))
+	require.Equal(t, "build (0a1b)", stripCorrelationID("build (0a1b)", ""))
+}
@@ -0,0 +1,224 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the values of sensitive inputs in the history.
+const Redacted = "***"
+
+// sensitiveInputPattern matches the names of inputs whose values are not written to the history. Names are split into words first, so that, for example, `auth_header` matches but `author` does not.
+var sensitiveInputPattern = regexp.MustCompile(`(^|_)(secrets?|tokens?|passwords?|passwd|credentials?|private|api_?key|auth)(_|$)`)
+
+// camelCasePattern and separatorPattern find the boundaries between the words of an input name, so that they can all be turned into underscores.
+var camelCasePattern = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+var separatorPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// sensitiveValuePattern matches values that look like credentials whatever the name of the input they are passed through, such as GitHub tokens.
+var sensitiveValuePattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|AKIA[0-9A-Z]{16}|xox[abprs]-[A-Za-z0-9-]{10,})|-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+
+func isSensitiveInputName(name string) bool {
+	words := separatorPattern.ReplaceAllString(camelCasePattern.ReplaceAllString(name, "${1}_${2}"), "_")
+	return sensitiveInputPattern.MatchString(strings.ToLower(words))
+}
+
+// Record describes a single dispatch. Once the run has completed, a second record with the same run is appended carrying just its conclusion.
+type Record struct {
+	Time       time.Time         `json:"time"`
+	Host       string            `json:"host"`
+	Owner      string            `json:"owner"`
+	Repository string            `json:"repository"`
+	Ref        string            `json:"ref,omitempty"`
+	Workflow   string            `json:"workflow,omitempty"`
+	Event      string            `json:"event,omitempty"`
+	Inputs     map[string]string `json:"inputs,omitempty"`
+	// CorrelationInput is the input the dispatch passed its correlation ID through, so that the ID is not reused when dispatching again.
+	CorrelationInput string `json:"correlation_input,omitempty"`
+	RunID            int64  `json:"run_id,omitempty"`
+	URL              string `json:"url,omitempty"`
+	Conclusion       string `json:"conclusion,omitempty"`
+}
+
+var fileMutex sync.Mutex
+
+func Path() string {
+	return filepath.Join(config.StateDir(), "gh-dispatch", "history.jsonl")
+}
+
+// Redact replaces the values of inputs that look like they hold secrets, either because of their name or because of their value.
+func Redact(inputs map[string]string) map[string]string {
+	redactedInputs := map[string]string{}
+	for key, value := range inputs {
+		if isSensitiveInputName(key) || sensitiveValuePattern.MatchString(value) {
+			value = Redacted
+		}
+		redactedInputs[key] = value
+	}
+	return redactedInputs
+}
+
+// NewRecord describes a workflow dispatch. The run may be nil if it was not located.
+func NewRecord(repository repository.Repository, ref string, workflow string, inputs map[string]string, workflowRun *run.WorkflowRun) Record {
+	record := Record{
+		Time:       time.Now().UTC(),
+		Host:       repository.Host,
+		Owner:      repository.Owner,
+		Repository: repository.Name,
+		Ref:        ref,
+		Workflow:   workflow,
+		Inputs:     Redact(inputs),
+	}
+	if workflowRun != nil {
+		record.RunID = workflowRun.ID
+		record.URL = workflowRun.HTMLURL
+		record.Conclusion = workflowRun.Conclusion
+	}
+	return record
+}
+
+// Add appends the record to the history. The history is only a convenience, so failures to write it are logged rather than returned.
+func Add(record Record) {
+	if err := AppendFile(Path(), record); err != nil {
+		log.Warnf("Unable to record dispatch in history: %s", err)
+	}
+}
+
+// AddConclusion records the conclusion of a completed run that was previously added to the history.
+func AddConclusion(repository repository.Repository, workflowRun *run.WorkflowRun) {
+	Add(Record{
+		Time:       time.Now().UTC(),
+		Host:       repository.Host,
+		Owner:      repository.Owner,
+		Repository: repository.Name,
+		RunID:      workflowRun.ID,
+		Conclusion: workflowRun.Conclusion,
+	})
+}
+
+func AppendFile(path string, record Record) error {
+	encodedRecord, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode history record.")
+	}
+
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "Unable to create history directory.")
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open history.")
+	}
+	if _, err := file.Write(append(encodedRecord, '\n')); err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "Unable to write history.")
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "Unable to write history.")
+	}
+	return nil
+}
+
+// ReadFile reads the history, oldest first, with conclusions merged into the records of the dispatches they belong to.
+// A missing history file is treated as an empty history.
+func ReadFile(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open history.")
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	records := []Record{}
+	runIndexes := map[string]int{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Wrapf(err, "Unable to parse line %d of history.", line)
+		}
+		key := record.runKey()
+		if index, ok := runIndexes[key]; ok && record.RunID != 0 {
+			if record.Conclusion != "" {
+				records[index].Conclusion = record.Conclusion
+			}
+			continue
+		}
+		if record.Workflow == "" && record.Event == "" {
+			// A conclusion for a dispatch that is not in the history.
+			continue
+		}
+		if record.RunID != 0 {
+			runIndexes[key] = len(records)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to read history.")
+	}
+	return records, nil
+}
+
+func Read() ([]Record, error) {
+	return ReadFile(Path())
+}
+
+func (record Record) runKey() string {
+	return strings.Join([]string{record.Host, record.Owner, record.Repository, strconv.FormatInt(record.RunID, 10)}, "/")
+}
+
+func (record Record) FullRepository() string {
+	return record.Owner + "/" + record.Repository
+}
+
+// Filter selects records from the history. Empty fields match every record.
+type Filter struct {
+	Repository string
+	Workflow   string
+	Ref        string
+	Conclusion string
+	Since      time.Time
+}
+
+func (filter Filter) Matches(record Record) bool {
+	if filter.Repository != "" && !strings.EqualFold(filter.Repository, record.FullRepository()) && !strings.EqualFold(filter.Repository, record.Host+"/"+record.FullRepository()) {
+		return false
+	}
+	if filter.Workflow != "" && filter.Workflow != record.Workflow && filter.Workflow != record.Event {
+		return false
+	}
+	if filter.Ref != "" && refs.ShortName(filter.Ref) != refs.ShortName(record.Ref) {
+		return false
+	}
+	if filter.Conclusion != "" && filter.Conclusion != record.Conclusion {
+		return false
+	}
+	if !filter.Since.IsZero() && record.Time.Before(filter.Since) {
+		return false
+	}
+	return true
+}
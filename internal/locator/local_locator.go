@@ -48,7 +48,7 @@ func (LocalLocator) ListWorkflows() (map[string]workflow.Workflow, error) {
 			log.Warnf("Workflow \"%s\" is invalid: %s", entry.Name(), err)
 			continue
 		}
-		if !loaded.Dispatchable {
+		if !loaded.Dispatchable && !loaded.RepositoryDispatchable {
 			continue
 		}
 		workflows[loaded.Name] = *loaded
@@ -2,6 +2,7 @@ package locator
 
 import "github.com/chrisgavin/gh-dispatch/internal/workflow"
 
+// Locator lists the workflows in a repository that can be triggered by either a workflow_dispatch or a repository_dispatch event.
 type Locator interface {
 	ListWorkflows() (map[string]workflow.Workflow, error)
 }
@@ -85,7 +85,7 @@ func (locator RemoteLocator) ListWorkflows() (map[string]workflow.Workflow, erro
 				channel <- concurrentWorkflowResult{}
 				return
 			}
-			if !loaded.Dispatchable {
+			if !loaded.Dispatchable && !loaded.RepositoryDispatchable {
 				channel <- concurrentWorkflowResult{}
 				return
 			}
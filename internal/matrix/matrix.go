@@ -0,0 +1,72 @@
+package matrix
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Dimension struct {
+	Key    string
+	Values []string
+}
+
+type Matrix []Dimension
+
+// Parse reads matrix dimensions given as `key=v1,v2,...`, preserving the order in which they were given.
+func Parse(arguments []string) (Matrix, error) {
+	matrix := Matrix{}
+	seen := map[string]bool{}
+	for _, argument := range arguments {
+		parts := strings.SplitN(argument, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("Matrix dimension %s is not of the form key=value1,value2.", argument)
+		}
+		if seen[parts[0]] {
+			return nil, errors.Errorf("Matrix dimension %s was given more than once.", parts[0])
+		}
+		seen[parts[0]] = true
+		values := strings.Split(parts[1], ",")
+		for _, value := range values {
+			if value == "" {
+				return nil, errors.Errorf("Matrix dimension %s contains an empty value.", parts[0])
+			}
+		}
+		matrix = append(matrix, Dimension{Key: parts[0], Values: values})
+	}
+	return matrix, nil
+}
+
+func (matrix Matrix) Size() int {
+	if len(matrix) == 0 {
+		return 0
+	}
+	size := 1
+	for _, dimension := range matrix {
+		size *= len(dimension.Values)
+	}
+	return size
+}
+
+// Combinations returns the cartesian product of the matrix, varying the last dimension fastest.
+func (matrix Matrix) Combinations() []map[string]string {
+	if len(matrix) == 0 {
+		return nil
+	}
+	combinations := []map[string]string{{}}
+	for _, dimension := range matrix {
+		expanded := []map[string]string{}
+		for _, combination := range combinations {
+			for _, value := range dimension.Values {
+				next := map[string]string{}
+				for key, existing := range combination {
+					next[key] = existing
+				}
+				next[dimension.Key] = value
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+	return combinations
+}
@@ -0,0 +1,42 @@
+package matrix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatrix(t *testing.T) {
+	matrix, err := Parse([]string{"size=small,large", "engine=a,b,c"})
+	require.NoError(t, err)
+	require.Equal(t, Matrix{
+		{Key: "size", Values: []string{"small", "large"}},
+		{Key: "engine", Values: []string{"a", "b", "c"}},
+	}, matrix)
+	require.Equal(t, 6, matrix.Size())
+}
+
+func TestParseInvalidMatrix(t *testing.T) {
+	_, err := Parse([]string{"size"})
+	require.Error(t, err)
+	_, err = Parse([]string{"size=a,,b"})
+	require.Error(t, err)
+	_, err = Parse([]string{"size=a", "size=b"})
+	require.Error(t, err)
+}
+
+func TestMatrixCombinations(t *testing.T) {
+	matrix, err := Parse([]string{"size=small,large", "engine=a,b"})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"size": "small", "engine": "a"},
+		{"size": "small", "engine": "b"},
+		{"size": "large", "engine": "a"},
+		{"size": "large", "engine": "b"},
+	}, matrix.Combinations())
+}
+
+func TestEmptyMatrix(t *testing.T) {
+	require.Equal(t, 0, Matrix{}.Size())
+	require.Empty(t, Matrix{}.Combinations())
+}
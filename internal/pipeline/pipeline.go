@@ -0,0 +1,229 @@
+package pipeline
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/batch"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+type Step struct {
+	ID          string   `yaml:"id"`
+	Needs       []string `yaml:"needs"`
+	batch.Entry `yaml:",inline"`
+}
+
+type Pipeline struct {
+	Hostname string `yaml:"hostname"`
+	Steps    []Step `yaml:"steps"`
+}
+
+type StepResult struct {
+	Step Step
+	spec.Result
+}
+
+// expressionPattern matches references to the outputs of earlier steps, such as `${{ steps.build.run_id }}`.
+var expressionPattern = regexp.MustCompile(`\$\{\{\s*steps\.([A-Za-z0-9_-]+)\.([A-Za-z_]+)\s*\}\}`)
+
+var stepOutputs = map[string]func(result spec.Result) string{
+	"run_id": func(result spec.Result) string {
+		return strconv.FormatInt(result.Run.ID, 10)
+	},
+	"run_url": func(result spec.Result) string {
+		return result.Run.HTMLURL
+	},
+	"conclusion": func(result spec.Result) string {
+		return result.Run.Conclusion
+	},
+	"ref": func(result spec.Result) string {
+		return result.Spec.Ref
+	},
+}
+
+func ReadPipeline(rawPipeline []byte) (*Pipeline, error) {
+	pipeline := Pipeline{}
+	if err := yaml.Unmarshal(rawPipeline, &pipeline); err != nil {
+		return nil, errors.Wrap(err, "Unable to parse pipeline as YAML.")
+	}
+	if err := pipeline.validate(); err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+func (pipeline Pipeline) validate() error {
+	if len(pipeline.Steps) == 0 {
+		return errors.New("Pipeline does not contain any steps.")
+	}
+	steps := map[string]Step{}
+	for index, step := range pipeline.Steps {
+		if step.ID == "" {
+			return errors.Errorf("Pipeline step %d has no id.", index+1)
+		}
+		if _, ok := steps[step.ID]; ok {
+			return errors.Errorf("Pipeline step id %s is used more than once.", step.ID)
+		}
+		if step.Repository == "" {
+			return errors.Errorf("Pipeline step %s has no repository.", step.ID)
+		}
+		if step.Workflow == "" {
+			return errors.Errorf("Pipeline step %s has no workflow.", step.ID)
+		}
+		steps[step.ID] = step
+	}
+	for _, step := range pipeline.Steps {
+		for _, need := range step.Needs {
+			if _, ok := steps[need]; !ok {
+				return errors.Errorf("Pipeline step %s needs unknown step %s.", step.ID, need)
+			}
+		}
+	}
+
+	// Check for cycles by repeatedly removing steps whose needs have all been removed already.
+	ancestors := map[string]map[string]bool{}
+	for len(ancestors) < len(steps) {
+		progress := false
+		for _, step := range pipeline.Steps {
+			if _, ok := ancestors[step.ID]; ok {
+				continue
+			}
+			stepAncestors := map[string]bool{}
+			ready := true
+			for _, need := range step.Needs {
+				needAncestors, ok := ancestors[need]
+				if !ok {
+					ready = false
+					break
+				}
+				stepAncestors[need] = true
+				for ancestor := range needAncestors {
+					stepAncestors[ancestor] = true
+				}
+			}
+			if ready {
+				ancestors[step.ID] = stepAncestors
+				progress = true
+			}
+		}
+		if !progress {
+			return errors.New("Pipeline steps have circular needs.")
+		}
+	}
+
+	for _, step := range pipeline.Steps {
+		for key, value := range step.Inputs {
+			stringValue, ok := value.(string)
+			if !ok {
+				continue
+			}
+			for _, match := range expressionPattern.FindAllStringSubmatch(stringValue, -1) {
+				if !ancestors[step.ID][match[1]] {
+					return errors.Errorf("Input %s of pipeline step %s refers to step %s, which it does not need.", key, step.ID, match[1])
+				}
+				if _, ok := stepOutputs[match[2]]; !ok {
+					return errors.Errorf("Input %s of pipeline step %s refers to unknown output %s.", key, step.ID, match[2])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// substitute replaces references to the outputs of earlier steps in the inputs with their values.
+func substitute(inputs map[string]string, results map[string]spec.Result) map[string]string {
+	substituted := map[string]string{}
+	for key, value := range inputs {
+		substituted[key] = expressionPattern.ReplaceAllStringFunc(value, func(expression string) string {
+			match := expressionPattern.FindStringSubmatch(expression)
+			return stepOutputs[match[2]](results[match[1]])
+		})
+	}
+	return substituted
+}
+
+type completion struct {
+	id     string
+	result spec.Result
+}
+
+// Run dispatches each step once all the steps it needs have succeeded, and skips it if any of them did not.
+// Independent steps run concurrently. The results are returned in the order the steps were declared.
+// The pipeline's hostname is only used if no hostname is given.
+func (pipeline Pipeline) Run(hostname string) []StepResult {
+	if hostname == "" {
+		hostname = pipeline.Hostname
+	}
+
+	results := map[string]spec.Result{}
+	started := map[string]bool{}
+	completions := make(chan completion)
+	running := 0
+	for {
+		progress := true
+		for progress {
+			progress = false
+			for _, step := range pipeline.Steps {
+				if started[step.ID] {
+					continue
+				}
+				ready := true
+				blocked := false
+				for _, need := range step.Needs {
+					needResult, ok := results[need]
+					if !ok {
+						ready = false
+					} else if !needResult.Succeeded() {
+						blocked = true
+					}
+				}
+				if blocked {
+					log.Warnf("Skipping pipeline step %s because a step it needs did not succeed.", step.ID)
+					started[step.ID] = true
+					results[step.ID] = spec.Result{Err: spec.ErrSkipped}
+					progress = true
+					continue
+				}
+				if !ready {
+					continue
+				}
+
+				started[step.ID] = true
+				stepSpec, err := step.Spec(hostname)
+				if err != nil {
+					results[step.ID] = spec.Result{Err: err}
+					progress = true
+					continue
+				}
+				stepSpec.Inputs = substitute(stepSpec.Inputs, results)
+				log.Infof("Starting pipeline step %s.", step.ID)
+				running++
+				go func(id string, stepSpec spec.Spec) {
+					completions <- completion{id: id, result: spec.DispatchAll([]spec.Spec{stepSpec}, 1, true)[0]}
+				}(step.ID, stepSpec)
+			}
+		}
+		if running == 0 {
+			break
+		}
+		finished := <-completions
+		running--
+		results[finished.id] = finished.result
+	}
+
+	stepResults := []StepResult{}
+	for _, step := range pipeline.Steps {
+		stepResults = append(stepResults, StepResult{Step: step, Result: results[step.ID]})
+	}
+	return stepResults
+}
+
+// FormatNeeds renders the needs of a step for display.
+func (step Step) FormatNeeds() string {
+	return strings.Join(step.Needs, ", ")
+}
@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPipeline(t *testing.T) {
+	const pipelineContent = `
+steps:
+  - id: build
+    repository: owner/build
+    workflow: build.yml
+  - id: test
+    needs: [build]
+    repository: owner/tests
+    ref: main
+    workflow: integration.yml
+    inputs:
+      build_run: ${{ steps.build.run_id }}
+  - id: deploy
+    needs: [test]
+    repository: owner/deploy
+    workflow: deploy.yml
+    inputs:
+      build_url: ${{ steps.build.run_url }}
+`
+	pipeline, err := ReadPipeline([]byte(pipelineContent))
+	require.NoError(t, err)
+	require.Len(t, pipeline.Steps, 3)
+	require.Equal(t, "test", pipeline.Steps[1].ID)
+	require.Equal(t, []string{"build"}, pipeline.Steps[1].Needs)
+	require.Equal(t, "owner/tests", pipeline.Steps[1].Repository)
+	require.Equal(t, "main", pipeline.Steps[1].Ref)
+}
+
+func TestReadPipelineWithUnknownNeed(t *testing.T) {
+	const pipelineContent = `
+steps:
+  - id: test
+    needs: [build]
+    repository: owner/tests
+    workflow: integration.yml
+`
+	_, err := ReadPipeline([]byte(pipelineContent))
+	require.ErrorContains(t, err, "unknown step build")
+}
+
+func TestReadPipelineWithCycle(t *testing.T) {
+	const pipelineContent = `
+steps:
+  - id: a
+    needs: [b]
+    repository: owner/repo
+    workflow: a.yml
+  - id: b
+    needs: [a]
+    repository: owner/repo
+    workflow: b.yml
+`
+	_, err := ReadPipeline([]byte(pipelineContent))
+	require.ErrorContains(t, err, "circular")
+}
+
+func TestReadPipelineWithReferenceToUnneededStep(t *testing.T) {
+	const pipelineContent = `
+steps:
+  - id: build
+    repository: owner/build
+    workflow: build.yml
+  - id: test
+    repository: owner/tests
+    workflow: integration.yml
+    inputs:
+      build_run: ${{ steps.build.run_id }}
+`
+	_, err := ReadPipeline([]byte(pipelineContent))
+	require.ErrorContains(t, err, "does not need")
+}
+
+func TestReadPipelineWithUnknownOutput(t *testing.T) {
+	const pipelineContent = `
+steps:
+  - id: build
+    repository: owner/build
+    workflow: build.yml
+  - id: test
+    needs: [build]
+    repository: owner/tests
+    workflow: integration.yml
+    inputs:
+      build_run: ${{ steps.build.artifact }}
+`
+	_, err := ReadPipeline([]byte(pipelineContent))
+	require.ErrorContains(t, err, "unknown output artifact")
+}
+
+func TestSubstitute(t *testing.T) {
+	results := map[string]spec.Result{
+		"build": {Run: &run.WorkflowRun{ID: 1234, HTMLURL: "https://github.com/owner/build/actions/runs/1234", Conclusion: "success"}},
+	}
+	require.Equal(t, map[string]string{
+		"build_run": "1234",
+		"summary":   "Run https://github.com/owner/build/actions/runs/1234 finished with success.",
+		"plain":     "value",
+	}, substitute(map[string]string{
+		"build_run": "${{ steps.build.run_id }}",
+		"summary":   "Run ${{steps.build.run_url}} finished with ${{ steps.build.conclusion }}.",
+		"plain":     "value",
+	}, results))
+}
@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/chrisgavin/gh-dispatch/internal/default_ref"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyPath is where the policy is read from, always on the default branch so that it cannot be loosened from another branch.
+const PolicyPath = ".github/dispatch-policy.yml"
+
+// Selector picks out dispatches by their workflow, given as a file name or display name, or by the environment they deploy to.
+// A dispatch deploys to an environment if it passes it to an input of the environment type or an input named environment.
+type Selector struct {
+	Workflows    []string `yaml:"workflows"`
+	Environments []string `yaml:"environments"`
+}
+
+// ChoiceRestriction only allows some values of an input to be chosen when dispatching on particular refs.
+type ChoiceRestriction struct {
+	// Workflows limits the restriction to these workflows. If it is empty then the restriction applies to every workflow.
+	Workflows []string `yaml:"workflows"`
+	Input     string   `yaml:"input"`
+	Values    []string `yaml:"values"`
+	// Refs are the branches and tags, as short names or glob patterns, on which the values may be chosen.
+	Refs []string `yaml:"refs"`
+}
+
+type Policy struct {
+	// RequireConfirmation selects dispatches that the user has to confirm by typing the name of the repository.
+	RequireConfirmation Selector `yaml:"require_confirmation"`
+	// DefaultBranchOnly lists workflows that may only be dispatched on the default branch.
+	DefaultBranchOnly []string            `yaml:"default_branch_only"`
+	RestrictedChoices []ChoiceRestriction `yaml:"restricted_choices"`
+	// Production selects dispatches for which the warning about uncommitted or unpushed changes may not be skipped.
+	Production Selector `yaml:"production"`
+}
+
+// Dispatch describes a dispatch to check against the policy.
+type Dispatch struct {
+	Ref        string
+	DefaultRef string
+	Workflow   workflow.Workflow
+	Inputs     map[string]string
+	// SkippedUnpushedCheck is set when the user asked not to be warned about uncommitted or unpushed changes.
+	SkippedUnpushedCheck bool
+}
+
+type apiFile struct {
+	Content string `json:"content"`
+}
+
+var (
+	cacheMutex sync.Mutex
+	cache      = map[string]*Policy{}
+)
+
+func Parse(rawPolicy []byte) (*Policy, error) {
+	policy := Policy{}
+	if err := yaml.Unmarshal(rawPolicy, &policy); err != nil {
+		return nil, errors.Wrap(err, "Unable to parse dispatch policy as YAML.")
+	}
+	for index, restriction := range policy.RestrictedChoices {
+		if restriction.Input == "" || len(restriction.Values) == 0 || len(restriction.Refs) == 0 {
+			return nil, errors.Errorf("Restricted choice %d of the dispatch policy must have an input, values and refs.", index+1)
+		}
+		for _, pattern := range restriction.Refs {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, errors.Errorf("Restricted choice %d of the dispatch policy has an invalid ref pattern %s.", index+1, pattern)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// Fetch reads the policy from the default branch of the repository. A repository without a policy gets an empty one, which allows everything.
+// Policies are cached, so each repository's policy is only fetched once.
+func Fetch(repository repository.Repository) (*Policy, error) {
+	key := fmt.Sprintf("%s/%s/%s", repository.Host, repository.Owner, repository.Name)
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if policy, ok := cache[key]; ok {
+		return policy, nil
+	}
+
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	file := apiFile{}
+	if err := client.Get(fmt.Sprintf("repos/%s/%s/contents/%s", repository.Owner, repository.Name, PolicyPath), &file); err != nil {
+		if httpError, ok := err.(*api.HTTPError); !ok || httpError.StatusCode != 404 {
+			return nil, errors.Wrap(err, "Unable to get dispatch policy.")
+		}
+	} else {
+		content, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode dispatch policy.")
+		}
+		policy, err = Parse(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cache[key] = policy
+	return policy, nil
+}
+
+// Enforce checks the dispatch against the repository's policy, returning whether the user has to confirm it.
+func Enforce(repository repository.Repository, dispatch Dispatch) (bool, error) {
+	policy, err := Fetch(repository)
+	if err != nil {
+		return false, err
+	}
+	if dispatch.DefaultRef == "" && len(policy.DefaultBranchOnly) > 0 {
+		dispatch.DefaultRef, err = default_ref.GetDefaultRef(repository)
+		if err != nil {
+			return false, err
+		}
+	}
+	if err := policy.Check(dispatch); err != nil {
+		return false, err
+	}
+	return policy.RequireConfirmation.matches(dispatch), nil
+}
+
+// Check returns an error describing the first rule of the policy that the dispatch breaks.
+func (policy Policy) Check(dispatch Dispatch) error {
+	if matchesWorkflow(policy.DefaultBranchOnly, dispatch.Workflow) && dispatch.Ref != dispatch.DefaultRef {
+		return errors.Errorf("The dispatch policy only allows %s to be dispatched on the default branch %s.", dispatch.Workflow.Name, refs.ShortName(dispatch.DefaultRef))
+	}
+	for _, restriction := range policy.RestrictedChoices {
+		if len(restriction.Workflows) > 0 && !matchesWorkflow(restriction.Workflows, dispatch.Workflow) {
+			continue
+		}
+		value, ok := dispatch.Inputs[restriction.Input]
+		if !ok || !contains(restriction.Values, value) || matchesRef(restriction.Refs, dispatch.Ref) {
+			continue
+		}
+		return errors.Errorf("The dispatch policy only allows %s to be set to %s on %s.", restriction.Input, value, strings.Join(restriction.Refs, ", "))
+	}
+	if dispatch.SkippedUnpushedCheck && policy.Production.matches(dispatch) {
+		return errors.New("The dispatch policy does not allow --no-prompt-unpushed to be used for production dispatches.")
+	}
+	return nil
+}
+
+func (selector Selector) matches(dispatch Dispatch) bool {
+	if matchesWorkflow(selector.Workflows, dispatch.Workflow) {
+		return true
+	}
+	for _, input := range dispatch.Workflow.Inputs {
+		if input.Type != workflow.EnvironmentInput && input.Name != "environment" {
+			continue
+		}
+		if value, ok := dispatch.Inputs[input.Name]; ok && contains(selector.Environments, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWorkflow(names []string, dispatchWorkflow workflow.Workflow) bool {
+	for _, name := range names {
+		if name == dispatchWorkflow.Name || (dispatchWorkflow.DisplayName != "" && name == dispatchWorkflow.DisplayName) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRef(patterns []string, reference string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, refs.ShortName(reference)); matched {
+			return true
+		}
+		if pattern == reference {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
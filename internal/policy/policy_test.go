@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+require_confirmation:
+  workflows: [Release]
+  environments: [production]
+default_branch_only: [release.yml]
+restricted_choices:
+  - input: environment
+    values: [production]
+    refs: [main, "release/*"]
+production:
+  environments: [production]
+`
+
+var deployWorkflow = workflow.Workflow{
+	Name:   "deploy.yml",
+	Inputs: []workflow.Input{{Name: "target", Type: workflow.EnvironmentInput}, {Name: "environment", Type: workflow.ChoiceInput}},
+}
+
+func parseTestPolicy(t *testing.T) *Policy {
+	policy, err := Parse([]byte(testPolicy))
+	require.NoError(t, err)
+	return policy
+}
+
+func TestDefaultBranchOnly(t *testing.T) {
+	policy := parseTestPolicy(t)
+	releaseWorkflow := workflow.Workflow{Name: "release.yml", DisplayName: "Release"}
+	require.NoError(t, policy.Check(Dispatch{Ref: "refs/heads/main", DefaultRef: "refs/heads/main", Workflow: releaseWorkflow}))
+	require.Error(t, policy.Check(Dispatch{Ref: "refs/heads/feature", DefaultRef: "refs/heads/main", Workflow: releaseWorkflow}))
+	require.NoError(t, policy.Check(Dispatch{Ref: "refs/heads/feature", DefaultRef: "refs/heads/main", Workflow: deployWorkflow}))
+}
+
+func TestRestrictedChoices(t *testing.T) {
+	policy := parseTestPolicy(t)
+	require.NoError(t, policy.Check(Dispatch{Ref: "refs/heads/release/1.2", Workflow: deployWorkflow, Inputs: map[string]string{"environment": "production"}}))
+	require.NoError(t, policy.Check(Dispatch{Ref: "refs/heads/feature", Workflow: deployWorkflow, Inputs: map[string]string{"environment": "staging"}}))
+	require.Error(t, policy.Check(Dispatch{Ref: "refs/heads/feature", Workflow: deployWorkflow, Inputs: map[string]string{"environment": "production"}}))
+}
+
+func TestProductionForbidsSkippingUnpushedCheck(t *testing.T) {
+	policy := parseTestPolicy(t)
+	require.Error(t, policy.Check(Dispatch{Ref: "refs/heads/main", Workflow: deployWorkflow, Inputs: map[string]string{"target": "production"}, SkippedUnpushedCheck: true}))
+	require.NoError(t, policy.Check(Dispatch{Ref: "refs/heads/main", Workflow: deployWorkflow, Inputs: map[string]string{"target": "staging"}, SkippedUnpushedCheck: true}))
+}
+
+func TestRequireConfirmation(t *testing.T) {
+	policy := parseTestPolicy(t)
+	require.True(t, policy.RequireConfirmation.matches(Dispatch{Workflow: workflow.Workflow{Name: "release.yml", DisplayName: "Release"}}))
+	require.True(t, policy.RequireConfirmation.matches(Dispatch{Workflow: deployWorkflow, Inputs: map[string]string{"target": "production"}}))
+	require.False(t, policy.RequireConfirmation.matches(Dispatch{Workflow: deployWorkflow, Inputs: map[string]string{"target": "staging"}}))
+}
+
+func TestParseInvalidPolicy(t *testing.T) {
+	_, err := Parse([]byte("restricted_choices:\n  - input: environment\n    values: [production]\n"))
+	require.Error(t, err)
+}
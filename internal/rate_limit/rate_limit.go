@@ -0,0 +1,36 @@
+package rate_limit
+
+import (
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/pkg/errors"
+)
+
+type Resource struct {
+	Limit     int   `json:"limit"`
+	Used      int   `json:"used"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+}
+
+func (resource Resource) ResetTime() time.Time {
+	return time.Unix(resource.Reset, 0)
+}
+
+type rateLimits struct {
+	Resources map[string]Resource `json:"resources"`
+}
+
+// GetRateLimits returns the current user's remaining budget for each of the API's rate limits. Checking them does not count against any of them.
+func GetRateLimits(host string) (map[string]Resource, error) {
+	client, err := client.NewClient(host)
+	if err != nil {
+		return nil, err
+	}
+	response := rateLimits{}
+	if err := client.Get("rate_limit", &response); err != nil {
+		return nil, errors.Wrap(err, "Unable to get rate limits.")
+	}
+	return response.Resources, nil
+}
@@ -0,0 +1,259 @@
+package refs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/chrisgavin/gh-dispatch/internal/default_ref"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const branchPrefix = "refs/heads/"
+const tagPrefix = "refs/tags/"
+
+var shaPattern = regexp.MustCompile("^[0-9a-fA-F]{7,40}$")
+
+type gitReference struct {
+	Ref string `json:"ref"`
+}
+
+type commit struct {
+	SHA string `json:"sha"`
+}
+
+type branch struct {
+	Name string `json:"name"`
+}
+
+type comparison struct {
+	Status string `json:"status"`
+}
+
+// ShortName strips the branch or tag prefix from a fully qualified ref.
+func ShortName(reference string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(reference, branchPrefix), tagPrefix)
+}
+
+func IsTag(reference string) bool {
+	return strings.HasPrefix(reference, tagPrefix)
+}
+
+// refLookup is the part of the GitHub API that resolving a ref needs.
+type refLookup interface {
+	refExists(reference string) (bool, error)
+	// commitSHA returns the full SHA of a commit, or an empty string if there is no such commit.
+	commitSHA(sha string) (string, error)
+	branchesWhereHead(sha string) ([]string, error)
+	defaultBranch() (string, error)
+	listBranches() ([]string, error)
+	branchContains(branchName string, sha string) (bool, error)
+}
+
+type apiRefLookup struct {
+	client     *api.RESTClient
+	repository repository.Repository
+}
+
+// Resolve turns a short ref given by the user into a fully qualified ref that a workflow can be dispatched on.
+// Branches are tried first, then tags, and finally commit SHAs, which are resolved to a branch containing the commit because workflows cannot be dispatched on a commit directly.
+func Resolve(repository repository.Repository, reference string) (string, error) {
+	if strings.HasPrefix(reference, "refs/") {
+		return reference, nil
+	}
+
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return "", err
+	}
+	return resolve(apiRefLookup{client: client, repository: repository}, repository, reference)
+}
+
+func resolve(lookup refLookup, repository repository.Repository, reference string) (string, error) {
+	matches := []string{}
+	for _, prefix := range []string{branchPrefix, tagPrefix} {
+		exists, err := lookup.refExists(prefix + reference)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			matches = append(matches, prefix+reference)
+		}
+	}
+	if len(matches) > 1 {
+		return "", errors.Errorf("The ref %s is ambiguous, as it matches %s. Please give the full ref instead.", reference, strings.Join(matches, " and "))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	if shaPattern.MatchString(reference) {
+		resolved, err := resolveCommit(lookup, reference)
+		if err != nil {
+			return "", err
+		}
+		if resolved != "" {
+			return resolved, nil
+		}
+	}
+
+	return "", errors.Errorf("The ref %s is not a branch, tag or commit in %s/%s.", reference, repository.Owner, repository.Name)
+}
+
+func resolveCommit(lookup refLookup, sha string) (string, error) {
+	fullSHA, err := lookup.commitSHA(sha)
+	if err != nil || fullSHA == "" {
+		return "", err
+	}
+
+	headBranches, err := lookup.branchesWhereHead(fullSHA)
+	if err != nil {
+		return "", err
+	}
+	if len(headBranches) == 1 {
+		return branchPrefix + headBranches[0], nil
+	}
+	if len(headBranches) > 1 {
+		return "", errors.Errorf("The commit %s is ambiguous, as it is the head of the branches %s. Please give a branch instead.", sha, strings.Join(headBranches, ", "))
+	}
+
+	// The commit is not the head of any branch, so the best we can do is find a branch that contains it, preferring the default branch.
+	defaultBranch, err := lookup.defaultBranch()
+	if err != nil {
+		return "", err
+	}
+	candidates := []string{defaultBranch}
+	branches, err := lookup.listBranches()
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range branches {
+		if candidate != defaultBranch {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	containingBranches := []string{}
+	for _, candidate := range candidates {
+		contains, err := lookup.branchContains(candidate, fullSHA)
+		if err != nil {
+			return "", err
+		}
+		if !contains {
+			continue
+		}
+		if candidate == defaultBranch {
+			containingBranches = []string{candidate}
+			break
+		}
+		containingBranches = append(containingBranches, candidate)
+	}
+	if len(containingBranches) == 0 {
+		return "", errors.Errorf("The commit %s is not contained in any branch, so a workflow cannot be dispatched on it.", sha)
+	}
+	if len(containingBranches) > 1 {
+		return "", errors.Errorf("The commit %s is ambiguous, as it is contained in the branches %s. Please give a branch instead.", sha, strings.Join(containingBranches, ", "))
+	}
+	log.Warnf("The commit %s is not the head of any branch. The workflow will be dispatched on the head of %s, which contains it.", sha, containingBranches[0])
+	return branchPrefix + containingBranches[0], nil
+}
+
+func (lookup apiRefLookup) refExists(reference string) (bool, error) {
+	response := gitReference{}
+	if err := lookup.client.Get(fmt.Sprintf("repos/%s/%s/git/ref/%s", lookup.repository.Owner, lookup.repository.Name, strings.TrimPrefix(reference, "refs/")), &response); err != nil {
+		if httpError, ok := err.(*api.HTTPError); ok && httpError.StatusCode == 404 {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "Unable to look up ref %s.", reference)
+	}
+	return response.Ref == reference, nil
+}
+
+func (lookup apiRefLookup) commitSHA(sha string) (string, error) {
+	resolvedCommit := commit{}
+	if err := lookup.client.Get(fmt.Sprintf("repos/%s/%s/commits/%s", lookup.repository.Owner, lookup.repository.Name, sha), &resolvedCommit); err != nil {
+		if httpError, ok := err.(*api.HTTPError); ok && (httpError.StatusCode == 404 || httpError.StatusCode == 422) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "Unable to look up commit %s.", sha)
+	}
+	return resolvedCommit.SHA, nil
+}
+
+func (lookup apiRefLookup) branchesWhereHead(sha string) ([]string, error) {
+	headBranches := []branch{}
+	if err := lookup.client.Get(fmt.Sprintf("repos/%s/%s/commits/%s/branches-where-head", lookup.repository.Owner, lookup.repository.Name, sha), &headBranches); err != nil {
+		return nil, errors.Wrapf(err, "Unable to find branches with commit %s at their head.", sha)
+	}
+	names := []string{}
+	for _, headBranch := range headBranches {
+		names = append(names, headBranch.Name)
+	}
+	return names, nil
+}
+
+func (lookup apiRefLookup) defaultBranch() (string, error) {
+	defaultReference, err := default_ref.GetDefaultRef(lookup.repository)
+	if err != nil {
+		return "", err
+	}
+	return ShortName(defaultReference), nil
+}
+
+func (lookup apiRefLookup) listBranches() ([]string, error) {
+	names := []string{}
+	for page := 1; ; page++ {
+		branches := []branch{}
+		if err := lookup.client.Get(fmt.Sprintf("repos/%s/%s/branches?per_page=100&page=%d", lookup.repository.Owner, lookup.repository.Name, page), &branches); err != nil {
+			return nil, errors.Wrap(err, "Unable to list branches.")
+		}
+		for _, branch := range branches {
+			names = append(names, branch.Name)
+		}
+		if len(branches) < 100 {
+			return names, nil
+		}
+	}
+}
+
+func (lookup apiRefLookup) branchContains(branchName string, sha string) (bool, error) {
+	result := comparison{}
+	if err := lookup.client.Get(fmt.Sprintf("repos/%s/%s/compare/%s...%s", lookup.repository.Owner, lookup.repository.Name, sha, url.PathEscape(branchName)), &result); err != nil {
+		return false, errors.Wrapf(err, "Unable to compare commit %s with branch %s.", sha, branchName)
+	}
+	return result.Status == "ahead" || result.Status == "identical", nil
+}
+
+// CreateBranch creates a branch pointing at the commit.
+func CreateBranch(repository repository.Repository, name string, sha string) error {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"ref": branchPrefix + name, "sha": sha})
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode ref.")
+	}
+	if err := client.Post(fmt.Sprintf("repos/%s/%s/git/refs", repository.Owner, repository.Name), bytes.NewReader(body), nil); err != nil {
+		return errors.Wrapf(err, "Unable to create branch %s.", name)
+	}
+	return nil
+}
+
+func DeleteBranch(repository repository.Repository, name string) error {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return err
+	}
+	if err := client.Delete(fmt.Sprintf("repos/%s/%s/git/refs/heads/%s", repository.Owner, repository.Name, name), nil); err != nil {
+		return errors.Wrapf(err, "Unable to delete branch %s.", name)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package refs
+
+import (
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortName(t *testing.T) {
+	require.Equal(t, "main", ShortName("refs/heads/main"))
+	require.Equal(t, "v1.2.3", ShortName("refs/tags/v1.2.3"))
+	require.Equal(t, "feature/thing", ShortName("refs/heads/feature/thing"))
+	require.Equal(t, "main", ShortName("main"))
+}
+
+func TestIsTag(t *testing.T) {
+	require.True(t, IsTag("refs/tags/v1.2.3"))
+	require.False(t, IsTag("refs/heads/v1.2.3"))
+}
+
+func TestShaPattern(t *testing.T) {
+	require.True(t, shaPattern.MatchString("0123abc"))
+	require.True(t, shaPattern.MatchString("0123456789abcdef0123456789abcdef01234567"))
+	require.False(t, shaPattern.MatchString("main"))
+	require.False(t, shaPattern.MatchString("0123ab"))
+}
+
+type fakeRefLookup struct {
+	refs        map[string]bool
+	commits     map[string]string
+	heads       map[string][]string
+	defaultName string
+	branches    []string
+	// containing maps each branch to the commits it contains.
+	containing map[string][]string
+}
+
+func (lookup fakeRefLookup) refExists(reference string) (bool, error) {
+	return lookup.refs[reference], nil
+}
+
+func (lookup fakeRefLookup) commitSHA(sha string) (string, error) {
+	return lookup.commits[sha], nil
+}
+
+func (lookup fakeRefLookup) branchesWhereHead(sha string) ([]string, error) {
+	return lookup.heads[sha], nil
+}
+
+func (lookup fakeRefLookup) defaultBranch() (string, error) {
+	return lookup.defaultName, nil
+}
+
+func (lookup fakeRefLookup) listBranches() ([]string, error) {
+	return lookup.branches, nil
+}
+
+func (lookup fakeRefLookup) branchContains(branchName string, sha string) (bool, error) {
+	for _, contained := range lookup.containing[branchName] {
+		if contained == sha {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+const fullSHA = "0123456789abcdef0123456789abcdef01234567"
+
+func TestResolve(t *testing.T) {
+	repository := repository.Repository{Host: "github.com", Owner: "owner", Name: "repo"}
+	tests := []struct {
+		name      string
+		lookup    fakeRefLookup
+		reference string
+		expected  string
+		err       string
+	}{
+		{
+			name:      "branch",
+			lookup:    fakeRefLookup{refs: map[string]bool{"refs/heads/main": true}},
+			reference: "main",
+			expected:  "refs/heads/main",
+		},
+		{
+			name:      "tag",
+			lookup:    fakeRefLookup{refs: map[string]bool{"refs/tags/v1.0.0": true}},
+			reference: "v1.0.0",
+			expected:  "refs/tags/v1.0.0",
+		},
+		{
+			name:      "branch and tag with the same name",
+			lookup:    fakeRefLookup{refs: map[string]bool{"refs/heads/release": true, "refs/tags/release": true}},
+			reference: "release",
+			err:       "The ref release is ambiguous, as it matches refs/heads/release and refs/tags/release. Please give the full ref instead.",
+		},
+		{
+			name:      "branch that looks like a commit",
+			lookup:    fakeRefLookup{refs: map[string]bool{"refs/heads/deadbeef": true}, commits: map[string]string{"deadbeef": fullSHA}},
+			reference: "deadbeef",
+			expected:  "refs/heads/deadbeef",
+		},
+		{
+			name:      "unknown ref",
+			lookup:    fakeRefLookup{},
+			reference: "missing",
+			err:       "The ref missing is not a branch, tag or commit in owner/repo.",
+		},
+		{
+			name:      "unknown commit",
+			lookup:    fakeRefLookup{},
+			reference: "0123456",
+			err:       "The ref 0123456 is not a branch, tag or commit in owner/repo.",
+		},
+		{
+			name:      "commit at the head of a branch",
+			lookup:    fakeRefLookup{commits: map[string]string{"0123456": fullSHA}, heads: map[string][]string{fullSHA: {"feature"}}},
+			reference: "0123456",
+			expected:  "refs/heads/feature",
+		},
+		{
+			name:      "commit at the head of several branches",
+			lookup:    fakeRefLookup{commits: map[string]string{"0123456": fullSHA}, heads: map[string][]string{fullSHA: {"feature", "other"}}},
+			reference: "0123456",
+			err:       "The commit 0123456 is ambiguous, as it is the head of the branches feature, other. Please give a branch instead.",
+		},
+		{
+			name: "commit contained in the default branch and others",
+			lookup: fakeRefLookup{
+				commits:     map[string]string{"0123456": fullSHA},
+				defaultName: "main",
+				branches:    []string{"feature", "main", "other"},
+				containing:  map[string][]string{"main": {fullSHA}, "feature": {fullSHA}, "other": {fullSHA}},
+			},
+			reference: "0123456",
+			expected:  "refs/heads/main",
+		},
+		{
+			name: "commit contained in one other branch",
+			lookup: fakeRefLookup{
+				commits:     map[string]string{"0123456": fullSHA},
+				defaultName: "main",
+				branches:    []string{"feature", "main", "other"},
+				containing:  map[string][]string{"other": {fullSHA}},
+			},
+			reference: "0123456",
+			expected:  "refs/heads/other",
+		},
+		{
+			name: "commit contained in several other branches",
+			lookup: fakeRefLookup{
+				commits:     map[string]string{"0123456": fullSHA},
+				defaultName: "main",
+				branches:    []string{"feature", "main", "other"},
+				containing:  map[string][]string{"feature": {fullSHA}, "other": {fullSHA}},
+			},
+			reference: "0123456",
+			err:       "The commit 0123456 is ambiguous, as it is contained in the branches feature, other. Please give a branch instead.",
+		},
+		{
+			name: "commit not contained in any branch",
+			lookup: fakeRefLookup{
+				commits:     map[string]string{"0123456": fullSHA},
+				defaultName: "main",
+				branches:    []string{"main"},
+			},
+			reference: "0123456",
+			err:       "The commit 0123456 is not contained in any branch, so a workflow cannot be dispatched on it.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := resolve(test.lookup, repository, test.reference)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, resolved)
+		})
+	}
+}
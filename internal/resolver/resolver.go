@@ -0,0 +1,164 @@
+package resolver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+)
+
+const maximumSuggestions = 5
+
+type UnknownWorkflowError struct {
+	Argument    string
+	Suggestions []string
+}
+
+func (err *UnknownWorkflowError) Error() string {
+	message := fmt.Sprintf("No dispatchable workflow matching \"%s\" was found.", err.Argument)
+	if len(err.Suggestions) > 0 {
+		message += fmt.Sprintf(" Did you mean %s?", strings.Join(err.Suggestions, ", "))
+	}
+	return message
+}
+
+type apiWorkflow struct {
+	Path string `json:"path"`
+}
+
+// ResolveWorkflow turns a workflow argument given by the user into the file name of one of the given workflows.
+// The argument may be a path, a file name, a display name, a numeric workflow ID or a URL to the workflow on GitHub.
+func ResolveWorkflow(repository repository.Repository, workflows map[string]workflow.Workflow, argument string) (string, error) {
+	if name, ok := matchWorkflow(workflows, argument); ok {
+		return name, nil
+	}
+
+	if id, err := strconv.ParseInt(argument, 10, 64); err == nil {
+		client, err := client.NewClient(repository.Host)
+		if err != nil {
+			return "", err
+		}
+		apiWorkflow := apiWorkflow{}
+		if err := client.Get(fmt.Sprintf("repos/%s/%s/actions/workflows/%d", repository.Owner, repository.Name, id), &apiWorkflow); err != nil {
+			if httpError, ok := err.(*api.HTTPError); !ok || httpError.StatusCode != 404 {
+				return "", errors.Wrapf(err, "Unable to get workflow with ID %d.", id)
+			}
+		} else if name, ok := matchWorkflow(workflows, apiWorkflow.Path); ok {
+			return name, nil
+		}
+	}
+
+	return "", &UnknownWorkflowError{
+		Argument:    argument,
+		Suggestions: suggestWorkflows(workflows, argument),
+	}
+}
+
+func matchWorkflow(workflows map[string]workflow.Workflow, argument string) (string, bool) {
+	if _, ok := workflows[argument]; ok {
+		return argument, true
+	}
+
+	fileName := argument
+	if index := strings.Index(fileName, "/actions/workflows/"); index >= 0 {
+		fileName = fileName[index+len("/actions/workflows/"):]
+		fileName = strings.SplitN(fileName, "?", 2)[0]
+		fileName = strings.SplitN(fileName, "#", 2)[0]
+	}
+	fileNameParts := strings.Split(strings.TrimSuffix(fileName, "/"), "/")
+	fileName = fileNameParts[len(fileNameParts)-1]
+	if _, ok := workflows[fileName]; ok {
+		return fileName, true
+	}
+
+	displayNameMatches := []string{}
+	for name, workflowData := range workflows {
+		if workflowData.DisplayName != "" && strings.EqualFold(workflowData.DisplayName, argument) {
+			displayNameMatches = append(displayNameMatches, name)
+		}
+	}
+	if len(displayNameMatches) == 1 {
+		return displayNameMatches[0], true
+	}
+
+	return "", false
+}
+
+func suggestWorkflows(workflows map[string]workflow.Workflow, argument string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	normalizedArgument := strings.ToLower(argument)
+	suggestions := []suggestion{}
+	for name, workflowData := range workflows {
+		best := -1
+		for _, candidate := range []string{name, trimWorkflowExtension(name), workflowData.DisplayName} {
+			if candidate == "" {
+				continue
+			}
+			normalizedCandidate := strings.ToLower(candidate)
+			distance := levenshteinDistance(normalizedArgument, normalizedCandidate)
+			if strings.Contains(normalizedCandidate, normalizedArgument) || strings.Contains(normalizedArgument, normalizedCandidate) {
+				distance = 0
+			}
+			if best < 0 || distance < best {
+				best = distance
+			}
+		}
+		if best >= 0 && best <= max(2, len(argument)/3) {
+			suggestions = append(suggestions, suggestion{name: name, distance: best})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+	names := []string{}
+	for _, suggestion := range suggestions {
+		if len(names) == maximumSuggestions {
+			break
+		}
+		names = append(names, suggestion.name)
+	}
+	return names
+}
+
+func trimWorkflowExtension(name string) string {
+	for _, extension := range workflow.WorkflowExtensions() {
+		if trimmed := strings.TrimSuffix(name, "."+extension); trimmed != name {
+			return trimmed
+		}
+	}
+	return name
+}
+
+func levenshteinDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,49 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var testWorkflows = map[string]workflow.Workflow{
+	"release.yml": {Name: "release.yml", DisplayName: "Release"},
+	"deploy.yaml": {Name: "deploy.yaml", DisplayName: "Deploy to Production"},
+	"build.yml":   {Name: "build.yml"},
+}
+
+func TestMatchWorkflowByFileName(t *testing.T) {
+	name, ok := matchWorkflow(testWorkflows, "release.yml")
+	require.True(t, ok)
+	require.Equal(t, "release.yml", name)
+}
+
+func TestMatchWorkflowByPath(t *testing.T) {
+	name, ok := matchWorkflow(testWorkflows, ".github/workflows/deploy.yaml")
+	require.True(t, ok)
+	require.Equal(t, "deploy.yaml", name)
+}
+
+func TestMatchWorkflowByDisplayName(t *testing.T) {
+	name, ok := matchWorkflow(testWorkflows, "deploy to production")
+	require.True(t, ok)
+	require.Equal(t, "deploy.yaml", name)
+}
+
+func TestMatchWorkflowByURL(t *testing.T) {
+	name, ok := matchWorkflow(testWorkflows, "https://github.com/owner/repo/actions/workflows/build.yml?query=branch%3Amain")
+	require.True(t, ok)
+	require.Equal(t, "build.yml", name)
+}
+
+func TestMatchUnknownWorkflow(t *testing.T) {
+	_, ok := matchWorkflow(testWorkflows, "test.yml")
+	require.False(t, ok)
+}
+
+func TestSuggestWorkflows(t *testing.T) {
+	require.Equal(t, []string{"release.yml"}, suggestWorkflows(testWorkflows, "relase"))
+	require.Equal(t, []string{"deploy.yaml"}, suggestWorkflows(testWorkflows, "deploy.yml"))
+	require.Empty(t, suggestWorkflows(testWorkflows, "something-else-entirely"))
+}
@@ -0,0 +1,40 @@
+package rollout
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Waves splits the items into consecutive waves of the given sizes, with any items left over going into a final wave.
+func Waves[T any](items []T, sizes []int) ([][]T, error) {
+	waves := [][]T{}
+	remaining := items
+	for _, size := range sizes {
+		if size < 1 {
+			return nil, errors.Errorf("Wave size %d must be at least 1.", size)
+		}
+		if len(remaining) == 0 {
+			break
+		}
+		size = min(size, len(remaining))
+		waves = append(waves, remaining[:size])
+		remaining = remaining[size:]
+	}
+	if len(remaining) > 0 {
+		waves = append(waves, remaining)
+	}
+	return waves, nil
+}
+
+// ParseRepositoryList reads a list of repositories with one per line, ignoring blank lines and `#` comments.
+func ParseRepositoryList(rawList string) []string {
+	repositories := []string{}
+	for _, line := range strings.Split(rawList, "\n") {
+		line = strings.TrimSpace(strings.SplitN(line, "#", 2)[0])
+		if line != "" {
+			repositories = append(repositories, line)
+		}
+	}
+	return repositories
+}
@@ -0,0 +1,41 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaves(t *testing.T) {
+	waves, err := Waves([]int{1, 2, 3, 4, 5, 6, 7, 8}, []int{1, 5})
+	require.NoError(t, err)
+	require.Equal(t, [][]int{{1}, {2, 3, 4, 5, 6}, {7, 8}}, waves)
+}
+
+func TestWavesLargerThanItems(t *testing.T) {
+	waves, err := Waves([]int{1, 2, 3}, []int{2, 5, 10})
+	require.NoError(t, err)
+	require.Equal(t, [][]int{{1, 2}, {3}}, waves)
+}
+
+func TestWavesWithoutSizes(t *testing.T) {
+	waves, err := Waves([]int{1, 2, 3}, nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]int{{1, 2, 3}}, waves)
+}
+
+func TestWavesWithInvalidSize(t *testing.T) {
+	_, err := Waves([]int{1, 2, 3}, []int{0})
+	require.Error(t, err)
+}
+
+func TestParseRepositoryList(t *testing.T) {
+	const list = `
+# Canaries
+owner/first
+owner/second # Flaky.
+
+github.example.com/owner/third
+`
+	require.Equal(t, []string{"owner/first", "owner/second", "github.example.com/owner/third"}, ParseRepositoryList(list))
+}
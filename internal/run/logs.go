@@ -0,0 +1,117 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+)
+
+// LogLine is a single line of a job's log, along with the time the runner wrote it.
+type LogLine struct {
+	Time time.Time
+	Text string
+}
+
+// parseLog splits a raw job log into lines, removing the timestamp the runner prefixes each line with.
+// Lines without a timestamp are given the time of the line before them.
+func parseLog(raw string) []LogLine {
+	raw = strings.TrimPrefix(raw, "\ufeff")
+	raw = strings.TrimSuffix(raw, "\n")
+	if raw == "" {
+		return []LogLine{}
+	}
+	lines := []LogLine{}
+	previousTime := time.Time{}
+	for _, text := range strings.Split(raw, "\n") {
+		text = strings.TrimSuffix(text, "\r")
+		line := LogLine{Time: previousTime, Text: text}
+		if timestamp, rest, ok := strings.Cut(text, " "); ok {
+			if parsed, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+				line = LogLine{Time: parsed, Text: rest}
+			}
+		}
+		previousTime = line.Time
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// GetJobLog downloads the log of a job. The log is only available once the job has completed.
+func GetJobLog(repository repository.Repository, jobID int64) ([]LogLine, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API redirects to a plain text download, which the client follows without passing on the credentials.
+	response, err := client.Request("GET", fmt.Sprintf("repos/%s/%s/actions/jobs/%d/logs", repository.Owner, repository.Name, jobID), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to get job log.")
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	raw, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read job log.")
+	}
+	return parseLog(string(raw)), nil
+}
+
+// FailedSteps returns the steps of the job that completed without succeeding.
+func (job Job) FailedSteps() []Step {
+	failed := []Step{}
+	for _, step := range job.Steps {
+		if step.Status == "completed" && (step.Conclusion == "failure" || step.Conclusion == "timed_out" || step.Conclusion == "cancelled") {
+			failed = append(failed, step)
+		}
+	}
+	return failed
+}
+
+// StepLog returns the lines of a job's log that were written by one of its steps.
+// The log does not say which step wrote each line, so each line is attributed to the last step that had started by the time it was written.
+// The API only gives the times of steps to the second, so lines written in the same second as a step started are attributed to that step.
+func (job Job) StepLog(lines []LogLine, number int) []LogLine {
+	stepLines := []LogLine{}
+	for _, line := range lines {
+		lineStep := 0
+		for _, step := range job.Steps {
+			if !step.StartedAt.IsZero() && !step.StartedAt.After(line.Time.Truncate(time.Second)) {
+				lineStep = step.Number
+			}
+		}
+		if lineStep == number {
+			stepLines = append(stepLines, line)
+		}
+	}
+	return stepLines
+}
+
+// Annotation is a message left on a job's check run, such as an error raised by a step or a warning from a problem matcher.
+type Annotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title"`
+	Message         string `json:"message"`
+}
+
+// ListAnnotations returns the annotations of a job. Jobs are check runs with the same ID, so their annotations come from the checks API.
+func ListAnnotations(repository repository.Repository, jobID int64) ([]Annotation, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := []Annotation{}
+	if err := client.Get(fmt.Sprintf("repos/%s/%s/check-runs/%d/annotations?per_page=100", repository.Owner, repository.Name, jobID), &annotations); err != nil {
+		return nil, errors.Wrap(err, "Unable to get job annotations.")
+	}
+	return annotations, nil
+}
@@ -0,0 +1,50 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLog(t *testing.T) {
+	lines := parseLog("\ufeff2026-10-18T12:00:00.1234567Z ##[group]Run make\r\n2026-10-18T12:00:01.0000000Z make: *** [test] Error 1\ncontinued\n")
+	require.Equal(t, []LogLine{
+		{Time: time.Date(2026, 10, 18, 12, 0, 0, 123456700, time.UTC), Text: "##[group]Run make"},
+		{Time: time.Date(2026, 10, 18, 12, 0, 1, 0, time.UTC), Text: "make: *** [test] Error 1"},
+		{Time: time.Date(2026, 10, 18, 12, 0, 1, 0, time.UTC), Text: "continued"},
+	}, lines)
+}
+
+func TestParseEmptyLog(t *testing.T) {
+	require.Empty(t, parseLog(""))
+}
+
+func TestStepLog(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	job := Job{Steps: []Step{
+		{Number: 1, Name: "Set up job", StartedAt: start},
+		{Number: 2, Name: "Build", StartedAt: start.Add(2 * time.Second)},
+		{Number: 3, Name: "Deploy"},
+		{Number: 4, Name: "Test", StartedAt: start.Add(10 * time.Second)},
+	}}
+	lines := []LogLine{
+		{Time: start.Add(500 * time.Millisecond), Text: "Runner version"},
+		{Time: start.Add(2500 * time.Millisecond), Text: "go build"},
+		{Time: start.Add(9 * time.Second), Text: "built"},
+		{Time: start.Add(10200 * time.Millisecond), Text: "go test"},
+		{Time: start.Add(12 * time.Second), Text: "FAIL"},
+	}
+	require.Equal(t, lines[1:3], job.StepLog(lines, 2))
+	require.Empty(t, job.StepLog(lines, 3))
+	require.Equal(t, lines[3:], job.StepLog(lines, 4))
+}
+
+func TestFailedSteps(t *testing.T) {
+	job := Job{Steps: []Step{
+		{Number: 1, Status: "completed", Conclusion: "success"},
+		{Number: 2, Status: "completed", Conclusion: "failure"},
+		{Number: 3, Status: "completed", Conclusion: "skipped"},
+	}}
+	require.Equal(t, []Step{job.Steps[1]}, job.FailedSteps())
+}
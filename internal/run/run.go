@@ -2,10 +2,12 @@ package run
 
 import (
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/cli/go-gh/v2/pkg/repository"
 	"github.com/pkg/errors"
@@ -16,19 +18,135 @@ type User struct {
 }
 
 type WorkflowRun struct {
-	ID         int64     `json:"id"`
-	Conclusion string    `json:"conclusion"`
-	Actor      User      `json:"actor"`
-	Branch     string    `json:"head_branch"`
-	Event      string    `json:"event"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID           int64     `json:"id"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	Actor        User      `json:"actor"`
+	Branch       string    `json:"head_branch"`
+	Event        string    `json:"event"`
+	DisplayTitle string    `json:"display_title"`
+	HTMLURL      string    `json:"html_url"`
+	CreatedAt    time.Time `json:"created_at"`
+	RunAttempt   int       `json:"run_attempt"`
+	Name         string    `json:"name"`
+	RunStartedAt time.Time `json:"run_started_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Path         string    `json:"path"`
 }
 
 type WorkflowRuns struct {
 	WorkflowRuns []WorkflowRun `json:"workflow_runs"`
 }
 
-func findRun(client *api.RESTClient, repository repository.Repository, reference string, after time.Time, before time.Time) (*WorkflowRun, error) {
+type Step struct {
+	Number      int       `json:"number"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+type Job struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	HTMLURL     string    `json:"html_url"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Steps       []Step    `json:"steps"`
+}
+
+type Jobs struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// matchesCorrelationID checks whether the correlation ID appears in the run's title (set with `run-name:`) or in the name of one of its jobs.
+func matchesCorrelationID(client *api.RESTClient, repository repository.Repository, run WorkflowRun, correlationID string) (bool, error) {
+	if strings.Contains(run.DisplayTitle, correlationID) {
+		return true, nil
+	}
+	jobs, err := listJobs(client, repository, run.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, job := range jobs {
+		if strings.Contains(job.Name, correlationID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func listJobs(client *api.RESTClient, repository repository.Repository, id int64) ([]Job, error) {
+	jobs := []Job{}
+	for page := 1; ; page++ {
+		response := Jobs{}
+		if err := client.Get(fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?per_page=100&page=%d", repository.Owner, repository.Name, id, page), &response); err != nil {
+			return nil, errors.Wrap(err, "Unable to get jobs for workflow run.")
+		}
+		jobs = append(jobs, response.Jobs...)
+		if len(response.Jobs) < 100 {
+			return jobs, nil
+		}
+	}
+}
+
+// ListJobs lists the jobs of the latest attempt of the run.
+func ListJobs(repository repository.Repository, id int64) ([]Job, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+	return listJobs(client, repository, id)
+}
+
+// Query describes the run created by a dispatch.
+type Query struct {
+	// Event is the event that triggered the run. If it is empty then workflow_dispatch is assumed.
+	Event     string
+	Reference string
+	// Workflow is the file name of the workflow the run belongs to, such as `release.yml`. If it is empty then runs of any workflow match.
+	Workflow string
+	// CorrelationID is the ID passed to the workflow through its correlation input, if it has one.
+	CorrelationID string
+	// ExcludedRunIDs are runs that have already been attributed to another dispatch.
+	ExcludedRunIDs map[int64]bool
+}
+
+// workflowFileName is the file name of the workflow a run belongs to. The path of a run can have the ref of the workflow appended to it, such as `.github/workflows/release.yml@refs/heads/main`.
+func (run WorkflowRun) workflowFileName() string {
+	workflowPath, _, _ := strings.Cut(run.Path, "@")
+	return path.Base(workflowPath)
+}
+
+// matches checks the details of a run that do not require any further requests against the query.
+func (query Query) matches(run WorkflowRun, login string, after time.Time, before time.Time) bool {
+	if query.ExcludedRunIDs[run.ID] {
+		return false
+	}
+	if !strings.EqualFold(run.Actor.Login, login) {
+		return false
+	}
+	// For runs dispatched on a tag, the head branch is the name of the tag.
+	if run.Branch != refs.ShortName(query.Reference) {
+		return false
+	}
+	event := query.Event
+	if event == "" {
+		event = "workflow_dispatch"
+	}
+	if run.Event != event {
+		return false
+	}
+	if query.Workflow != "" && run.workflowFileName() != query.Workflow {
+		return false
+	}
+	return !run.CreatedAt.Before(after) && !run.CreatedAt.After(before)
+}
+
+func findRun(client *api.RESTClient, repository repository.Repository, query Query, after time.Time, before time.Time) (*WorkflowRun, error) {
 	user := User{}
 	if err := client.Get("user", &user); err != nil {
 		return nil, errors.Wrap(err, "Unable to get the current user.")
@@ -40,24 +158,26 @@ func findRun(client *api.RESTClient, repository repository.Repository, reference
 	}
 
 	for _, run := range workflowRuns.WorkflowRuns {
-		if !strings.EqualFold(run.Actor.Login, user.Login) {
+		if !query.matches(run, user.Login, after, before) {
 			continue
 		}
-		if run.Branch != strings.TrimPrefix(reference, "refs/heads/") {
-			continue
-		}
-		if run.Event != "workflow_dispatch" {
-			continue
-		}
-		if run.CreatedAt.Before(after) || run.CreatedAt.After(before) {
-			continue
+		if query.CorrelationID != "" {
+			matches, err := matchesCorrelationID(client, repository, run, query.CorrelationID)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
 		}
 		return &run, nil
 	}
 	return nil, nil
 }
 
-func LocateRun(repository repository.Repository, reference string) (*WorkflowRun, error) {
+// LocateRun finds the run created by a dispatch.
+// If a correlation ID was passed to the workflow then only a run carrying that ID will match, otherwise the most recent matching run by the current user is assumed to be the right one.
+func LocateRun(repository repository.Repository, query Query) (*WorkflowRun, error) {
 	client, err := client.NewClient(repository.Host)
 	if err != nil {
 		return nil, err
@@ -68,7 +188,7 @@ func LocateRun(repository repository.Repository, reference string) (*WorkflowRun
 	before := currentTime.Add(1 * time.Minute)
 
 	for {
-		run, err := findRun(client, repository, reference, after, before)
+		run, err := findRun(client, repository, query, after, before)
 		if err != nil {
 			return nil, err
 		}
@@ -76,12 +196,33 @@ func LocateRun(repository repository.Repository, reference string) (*WorkflowRun
 			return run, nil
 		}
 		if time.Now().After(currentTime.Add(1 * time.Minute)) {
+			if query.CorrelationID != "" {
+				return nil, errors.Errorf("Workflow with correlation ID %s did not start within 1 minute. Does the workflow include the ID in its run-name or a job name?", query.CorrelationID)
+			}
 			return nil, errors.New("Workflow did not start within 1 minute.")
 		}
 		time.Sleep(3 * time.Second)
 	}
 }
 
+// ListRunIDs returns the IDs of the most recent runs triggered by the event, so that runs which already existed before a dispatch can be excluded when locating the runs it created.
+func ListRunIDs(repository repository.Repository, event string) (map[int64]bool, error) {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowRuns := WorkflowRuns{}
+	if err := client.Get(fmt.Sprintf("repos/%s/%s/actions/runs?event=%s&per_page=100", repository.Owner, repository.Name, event), &workflowRuns); err != nil {
+		return nil, errors.Wrap(err, "Unable to get list of recent runs.")
+	}
+	ids := map[int64]bool{}
+	for _, run := range workflowRuns.WorkflowRuns {
+		ids[run.ID] = true
+	}
+	return ids, nil
+}
+
 func GetRun(repository repository.Repository, id int64) (*WorkflowRun, error) {
 	client, err := client.NewClient(repository.Host)
 	if err != nil {
@@ -94,3 +235,57 @@ func GetRun(repository repository.Repository, id int64) (*WorkflowRun, error) {
 	}
 	return &workflowRun, nil
 }
+
+// WaitForRun polls the run until it has completed and returns its final state.
+func WaitForRun(repository repository.Repository, id int64) (*WorkflowRun, error) {
+	for {
+		workflowRun, err := GetRun(repository, id)
+		if err != nil {
+			return nil, err
+		}
+		if workflowRun.Status == "completed" {
+			return workflowRun, nil
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// AttemptURL is the URL of one attempt of the run, which stays the same after the run is re-run.
+func (run WorkflowRun) AttemptURL() string {
+	return fmt.Sprintf("%s/attempts/%d", run.HTMLURL, run.RunAttempt)
+}
+
+// Rerun starts a new attempt of a completed run, either of every job or only of the jobs that failed.
+func Rerun(repository repository.Repository, id int64, failedJobsOnly bool) error {
+	client, err := client.NewClient(repository.Host)
+	if err != nil {
+		return err
+	}
+
+	endpoint := "rerun"
+	if failedJobsOnly {
+		endpoint = "rerun-failed-jobs"
+	}
+	if err := client.Post(fmt.Sprintf("repos/%s/%s/actions/runs/%d/%s", repository.Owner, repository.Name, id, endpoint), nil, nil); err != nil {
+		return errors.Wrap(err, "Unable to re-run workflow run.")
+	}
+	return nil
+}
+
+// WaitForAttempt polls the run until the given attempt has started, since a re-run is not always reflected by the API straight away.
+func WaitForAttempt(repository repository.Repository, id int64, attempt int) (*WorkflowRun, error) {
+	deadline := time.Now().Add(1 * time.Minute)
+	for {
+		workflowRun, err := GetRun(repository, id)
+		if err != nil {
+			return nil, err
+		}
+		if workflowRun.RunAttempt >= attempt {
+			return workflowRun, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("Attempt %d of workflow run did not start within 1 minute.", attempt)
+		}
+		time.Sleep(3 * time.Second)
+	}
+}
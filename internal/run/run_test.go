@@ -0,0 +1,38 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryMatches(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	after, before := now.Add(-time.Minute), now.Add(time.Minute)
+	candidate := WorkflowRun{
+		ID:        1,
+		Actor:     User{Login: "Octocat"},
+		Branch:    "main",
+		Event:     "workflow_dispatch",
+		CreatedAt: now,
+		Path:      ".github/workflows/release.yml",
+	}
+	query := Query{Reference: "refs/heads/main", Workflow: "release.yml"}
+	require.True(t, query.matches(candidate, "octocat", after, before))
+
+	otherWorkflow := candidate
+	otherWorkflow.Path = ".github/workflows/deploy.yml"
+	require.False(t, query.matches(otherWorkflow, "octocat", after, before))
+	require.True(t, Query{Reference: "refs/heads/main"}.matches(otherWorkflow, "octocat", after, before))
+
+	withRef := candidate
+	withRef.Path = ".github/workflows/release.yml@refs/heads/main"
+	require.True(t, query.matches(withRef, "octocat", after, before))
+
+	require.False(t, query.matches(candidate, "someone-else", after, before))
+	require.False(t, Query{Reference: "refs/heads/other", Workflow: "release.yml"}.matches(candidate, "octocat", after, before))
+	require.False(t, Query{Reference: "refs/heads/main", Event: "repository_dispatch"}.matches(candidate, "octocat", after, before))
+	require.False(t, Query{Reference: "refs/heads/main", ExcludedRunIDs: map[int64]bool{1: true}}.matches(candidate, "octocat", after, before))
+	require.False(t, query.matches(candidate, "octocat", after.Add(2*time.Minute), before.Add(2*time.Minute)))
+}
@@ -0,0 +1,158 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/client"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/mattn/go-runewidth"
+	"github.com/pkg/errors"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+// Snapshot is the state of a run and its jobs at one point in time.
+type Snapshot struct {
+	Run  WorkflowRun
+	Jobs []Job
+}
+
+func (snapshot Snapshot) Completed() bool {
+	return snapshot.Run.Status == "completed"
+}
+
+// FailedJobs returns the jobs that completed without succeeding, skipping those that were skipped or cancelled as a result of another job failing.
+func (snapshot Snapshot) FailedJobs() []Job {
+	failed := []Job{}
+	for _, job := range snapshot.Jobs {
+		if job.Status == "completed" && job.Conclusion != "success" && job.Conclusion != "skipped" && job.Conclusion != "neutral" && job.Conclusion != "cancelled" {
+			failed = append(failed, job)
+		}
+	}
+	return failed
+}
+
+// Watcher polls a run and its jobs until the run completes.
+type Watcher struct {
+	Repository repository.Repository
+	// Interval is the time between polls. If it is zero then a default is used.
+	Interval time.Duration
+	// OnUpdate, if set, is called with every snapshot, including the final one.
+	OnUpdate func(snapshot Snapshot)
+}
+
+// Watch polls the run until it completes and returns its final state.
+func (watcher Watcher) Watch(ctx context.Context, id int64) (*Snapshot, error) {
+	client, err := client.NewClient(watcher.Repository.Host)
+	if err != nil {
+		return nil, err
+	}
+	interval := watcher.Interval
+	if interval == 0 {
+		interval = defaultWatchInterval
+	}
+
+	for {
+		snapshot := Snapshot{}
+		if err := client.Get(fmt.Sprintf("repos/%s/%s/actions/runs/%d", watcher.Repository.Owner, watcher.Repository.Name, id), &snapshot.Run); err != nil {
+			return nil, errors.Wrap(err, "Unable to get workflow run.")
+		}
+		snapshot.Jobs, err = listJobs(client, watcher.Repository, id)
+		if err != nil {
+			return nil, err
+		}
+		if watcher.OnUpdate != nil {
+			watcher.OnUpdate(snapshot)
+		}
+		if snapshot.Completed() {
+			return &snapshot, nil
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func symbol(status string, conclusion string) string {
+	switch status {
+	case "completed":
+		switch conclusion {
+		case "success":
+			return "✓"
+		case "skipped", "neutral":
+			return "-"
+		default:
+			return "X"
+		}
+	case "in_progress":
+		return "*"
+	default:
+		return "-"
+	}
+}
+
+// elapsed formats how long something has been running, or how long it ran for if it has completed.
+func elapsed(startedAt time.Time, completedAt time.Time, completed bool, now time.Time) string {
+	if startedAt.IsZero() {
+		return ""
+	}
+	end := now
+	if completed && !completedAt.IsZero() {
+		end = completedAt
+	}
+	duration := max(end.Sub(startedAt).Round(time.Second), 0)
+	return " (" + duration.String() + ")"
+}
+
+// fitToWidth truncates each line so that none of them wrap on a terminal of the given width.
+// Lines are cut one column short of the width, since some terminals wrap as soon as the last column is written.
+func fitToWidth(text string, width int) string {
+	if width < 2 {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for index, line := range lines {
+		content := strings.TrimSuffix(line, "\n")
+		if runewidth.StringWidth(content) > width-1 {
+			lines[index] = runewidth.Truncate(content, width-1, "…") + line[len(content):]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// Render draws the snapshot as a tree of the run, its jobs and, for jobs that are running or failed, their steps.
+// If width is not zero then each line is truncated to fit in a terminal that wide, so that the number of lines drawn is the number of lines rendered.
+func (snapshot Snapshot) Render(now time.Time, width int) string {
+	builder := strings.Builder{}
+	title := snapshot.Run.DisplayTitle
+	if title == "" {
+		title = snapshot.Run.Name
+	}
+	runCompleted := snapshot.Completed()
+	fmt.Fprintf(&builder, "%s %s%s\n", symbol(snapshot.Run.Status, snapshot.Run.Conclusion), title, elapsed(snapshot.Run.RunStartedAt, snapshot.Run.UpdatedAt, runCompleted, now))
+
+	for jobIndex, job := range snapshot.Jobs {
+		branch, indent := "├─ ", "│  "
+		if jobIndex == len(snapshot.Jobs)-1 {
+			branch, indent = "└─ ", "   "
+		}
+		jobCompleted := job.Status == "completed"
+		fmt.Fprintf(&builder, "%s%s %s%s\n", branch, symbol(job.Status, job.Conclusion), job.Name, elapsed(job.StartedAt, job.CompletedAt, jobCompleted, now))
+		if job.Status == "queued" || job.Status == "waiting" || (jobCompleted && job.Conclusion == "success") {
+			continue
+		}
+		for stepIndex, step := range job.Steps {
+			stepBranch := "├─ "
+			if stepIndex == len(job.Steps)-1 {
+				stepBranch = "└─ "
+			}
+			fmt.Fprintf(&builder, "%s%s%s %s%s\n", indent, stepBranch, symbol(step.Status, step.Conclusion), step.Name, elapsed(step.StartedAt, step.CompletedAt, step.Status == "completed", now))
+		}
+	}
+	return fitToWidth(builder.String(), width)
+}
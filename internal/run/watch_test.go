@@ -0,0 +1,55 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	snapshot := Snapshot{
+		Run: WorkflowRun{Status: "in_progress", DisplayTitle: "Deploy", RunStartedAt: start},
+		Jobs: []Job{
+			{Name: "build", Status: "completed", Conclusion: "success", StartedAt: start, CompletedAt: start.Add(45 * time.Second), Steps: []Step{{Name: "Compile", Status: "completed", Conclusion: "success"}}},
+			{Name: "test", Status: "in_progress", StartedAt: start.Add(50 * time.Second), Steps: []Step{
+				{Name: "Set up job", Status: "completed", Conclusion: "success", StartedAt: start.Add(50 * time.Second), CompletedAt: start.Add(52 * time.Second)},
+				{Name: "Run tests", Status: "in_progress", StartedAt: start.Add(52 * time.Second)},
+			}},
+			{Name: "deploy", Status: "queued"},
+		},
+	}
+	require.Equal(t, `* Deploy (1m30s)
+├─ ✓ build (45s)
+├─ * test (40s)
+│  ├─ ✓ Set up job (2s)
+│  └─ * Run tests (38s)
+└─ - deploy
+`, snapshot.Render(start.Add(90*time.Second), 0))
+}
+
+func TestRenderTruncatesToWidth(t *testing.T) {
+	snapshot := Snapshot{
+		Run:  WorkflowRun{Status: "queued", DisplayTitle: "Deploy the application to production"},
+		Jobs: []Job{{Name: "deploy 🚀 to every region", Status: "queued"}, {Name: "notify", Status: "queued"}},
+	}
+	// Lines are cut to 16 columns, where the rocket takes up two of them.
+	require.Equal(t, `- Deploy the ap…
+├─ - deploy 🚀 …
+└─ - notify
+`, snapshot.Render(time.Time{}, 17))
+}
+
+func TestFailedJobs(t *testing.T) {
+	snapshot := Snapshot{Jobs: []Job{
+		{Name: "build", Status: "completed", Conclusion: "failure"},
+		{Name: "lint", Status: "completed", Conclusion: "success"},
+		{Name: "deploy", Status: "completed", Conclusion: "skipped"},
+		{Name: "e2e", Status: "completed", Conclusion: "timed_out"},
+	}}
+	failed := snapshot.FailedJobs()
+	require.Len(t, failed, 2)
+	require.Equal(t, "build", failed[0].Name)
+	require.Equal(t, "e2e", failed[1].Name)
+}
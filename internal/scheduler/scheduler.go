@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chrisgavin/gh-dispatch/internal/batch"
+	"github.com/chrisgavin/gh-dispatch/internal/cron"
+	"github.com/chrisgavin/gh-dispatch/internal/history"
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/spec"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+type Entry struct {
+	// Name identifies the entry in the log. If it is empty then the repository and workflow are used instead.
+	Name        string `yaml:"name"`
+	Cron        string `yaml:"cron"`
+	batch.Entry `yaml:",inline"`
+
+	schedule *cron.Schedule
+}
+
+type Config struct {
+	Hostname  string  `yaml:"hostname"`
+	Log       string  `yaml:"log"`
+	Schedules []Entry `yaml:"schedules"`
+}
+
+// resolveAttempts and resolveBackoff control how often a failure to look up the ref or workflow is retried before the dispatch is skipped.
+// Dispatching itself is never retried, since a request that failed part way through may still have created a run.
+const (
+	resolveAttempts = 5
+	resolveBackoff  = 30 * time.Second
+	pollInterval    = 30 * time.Second
+)
+
+func ReadConfig(rawConfig []byte) (*Config, error) {
+	config := Config{}
+	if err := yaml.Unmarshal(rawConfig, &config); err != nil {
+		return nil, errors.Wrap(err, "Unable to parse schedule config as YAML.")
+	}
+	if len(config.Schedules) == 0 {
+		return nil, errors.New("Schedule config does not contain any schedules.")
+	}
+	for index := range config.Schedules {
+		entry := &config.Schedules[index]
+		if entry.Cron == "" {
+			return nil, errors.Errorf("Schedule %d has no cron expression.", index+1)
+		}
+		if entry.Repository == "" {
+			return nil, errors.Errorf("Schedule %d has no repository.", index+1)
+		}
+		if entry.Workflow == "" {
+			return nil, errors.Errorf("Schedule %d has no workflow.", index+1)
+		}
+		schedule, err := cron.Parse(entry.Cron)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid schedule %d.", index+1)
+		}
+		entry.schedule = schedule
+	}
+	return &config, nil
+}
+
+func (entry Entry) label() string {
+	if entry.Name != "" {
+		return entry.Name
+	}
+	return entry.Workflow + " on " + entry.Repository
+}
+
+// Run dispatches each entry whenever its cron expression matches, until the context is cancelled.
+// Every dispatch, and the conclusion of the run it creates, is written to the logger.
+// The config's hostname is only used if no hostname is given.
+func (config Config) Run(ctx context.Context, hostname string, logger *log.Logger) {
+	if hostname == "" {
+		hostname = config.Hostname
+	}
+
+	now := time.Now()
+	nextTimes := make([]time.Time, len(config.Schedules))
+	for index, entry := range config.Schedules {
+		nextTimes[index] = entry.schedule.Next(now)
+		logger.WithField("schedule", entry.label()).Infof("Next dispatch at %s.", nextTimes[index].Format(time.RFC3339))
+	}
+
+	waitGroup := sync.WaitGroup{}
+	defer waitGroup.Wait()
+	for {
+		earliest := time.Time{}
+		for _, nextTime := range nextTimes {
+			if !nextTime.IsZero() && (earliest.IsZero() || nextTime.Before(earliest)) {
+				earliest = nextTime
+			}
+		}
+		if earliest.IsZero() {
+			logger.Warn("No schedule will match again. Stopping.")
+			return
+		}
+
+		select {
+		case <-time.After(time.Until(earliest)):
+		case <-ctx.Done():
+			logger.Info("Stopping scheduler. Runs that have already been dispatched will continue.")
+			return
+		}
+
+		now := time.Now()
+		for index, entry := range config.Schedules {
+			if nextTimes[index].IsZero() || nextTimes[index].After(now) {
+				continue
+			}
+			// Schedules are computed from the current time rather than the previous dispatch, so that times missed while the machine was asleep are skipped rather than dispatched all at once.
+			nextTimes[index] = entry.schedule.Next(now)
+			waitGroup.Add(1)
+			go func(entry Entry) {
+				defer waitGroup.Done()
+				entry.dispatch(ctx, hostname, logger)
+			}(entry)
+		}
+	}
+}
+
+func (entry Entry) dispatch(ctx context.Context, hostname string, logger *log.Logger) {
+	entryLogger := logger.WithFields(log.Fields{"schedule": entry.label(), "repository": entry.Repository, "workflow": entry.Workflow})
+
+	entrySpec, err := entry.Spec(hostname)
+	if err != nil {
+		entryLogger.WithError(err).Error("Unable to dispatch.")
+		return
+	}
+	for attempt := 1; ; attempt++ {
+		entrySpec, err = spec.Resolve(entrySpec)
+		if err == nil {
+			break
+		}
+		if attempt == resolveAttempts {
+			entryLogger.WithError(err).Errorf("Unable to resolve after %d attempts. Skipping this dispatch.", attempt)
+			return
+		}
+		entryLogger.WithError(err).Warnf("Unable to resolve. Retrying in %s.", resolveBackoff*time.Duration(attempt))
+		if !sleep(ctx, resolveBackoff*time.Duration(attempt)) {
+			return
+		}
+	}
+	entryLogger = entryLogger.WithField("ref", entrySpec.Ref)
+
+	workflowRun, err := spec.Dispatch(entrySpec)
+	if err != nil {
+		entryLogger.WithError(err).Error("Unable to dispatch.")
+		return
+	}
+	entryLogger = entryLogger.WithField("run", workflowRun.HTMLURL)
+	entryLogger.Info("Dispatched.")
+
+	for workflowRun.Status != "completed" {
+		if !sleep(ctx, pollInterval) {
+			return
+		}
+		latestRun, err := run.GetRun(entrySpec.Repository, workflowRun.ID)
+		if err != nil {
+			entryLogger.WithError(err).Warn("Unable to check the status of the run. Will try again.")
+			continue
+		}
+		workflowRun = latestRun
+	}
+	history.AddConclusion(entrySpec.Repository, workflowRun)
+	conclusionLogger := entryLogger.WithField("conclusion", workflowRun.Conclusion)
+	if workflowRun.Conclusion == "success" {
+		conclusionLogger.Info("Completed.")
+	} else {
+		conclusionLogger.Error("Completed.")
+	}
+}
+
+// sleep waits for the duration and returns false if the context was cancelled first.
+func sleep(ctx context.Context, duration time.Duration) bool {
+	select {
+	case <-time.After(duration):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
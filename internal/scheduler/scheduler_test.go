@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfig(t *testing.T) {
+	const configContent = `
+hostname: github.example.com
+schedules:
+  - name: nightly maintenance
+    cron: "0 3 * * *"
+    repository: owner/fork
+    workflow: maintenance.yml
+    inputs:
+      prune: true
+  - cron: "@weekly"
+    repository: owner/other
+    workflow: Cleanup
+`
+	config, err := ReadConfig([]byte(configContent))
+	require.NoError(t, err)
+	require.Equal(t, "github.example.com", config.Hostname)
+	require.Len(t, config.Schedules, 2)
+	require.Equal(t, "nightly maintenance", config.Schedules[0].label())
+	require.Equal(t, "Cleanup on owner/other", config.Schedules[1].label())
+	require.NotNil(t, config.Schedules[1].schedule)
+	entrySpec, err := config.Schedules[0].Spec(config.Hostname)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"prune": "true"}, entrySpec.Inputs)
+}
+
+func TestReadConfigWithInvalidCron(t *testing.T) {
+	_, err := ReadConfig([]byte("schedules:\n  - cron: \"0 25 * * *\"\n    repository: owner/repo\n    workflow: build.yml\n"))
+	require.Error(t, err)
+	_, err = ReadConfig([]byte("schedules:\n  - repository: owner/repo\n    workflow: build.yml\n"))
+	require.Error(t, err)
+}
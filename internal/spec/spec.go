@@ -0,0 +1,275 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chrisgavin/gh-dispatch/internal/correlation"
+	"github.com/chrisgavin/gh-dispatch/internal/default_ref"
+	"github.com/chrisgavin/gh-dispatch/internal/dispatcher"
+	"github.com/chrisgavin/gh-dispatch/internal/history"
+	"github.com/chrisgavin/gh-dispatch/internal/locator"
+	"github.com/chrisgavin/gh-dispatch/internal/policy"
+	"github.com/chrisgavin/gh-dispatch/internal/refs"
+	"github.com/chrisgavin/gh-dispatch/internal/resolver"
+	"github.com/chrisgavin/gh-dispatch/internal/run"
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Spec describes a dispatch that can be made without prompting the user for anything.
+type Spec struct {
+	Repository repository.Repository
+	Ref        string
+	Workflow   string
+	Inputs     map[string]string
+	// CorrelationInput is the input a fresh correlation ID is passed through for each dispatch. If it is empty then the input is detected automatically.
+	CorrelationInput string
+	// Confirmed is set once the user has confirmed a dispatch that the repository's dispatch policy requires confirmation for.
+	Confirmed bool
+
+	workflowData *workflow.Workflow
+}
+
+var (
+	// Dispatches on the same repository and ref are serialised so that, if their runs have to be located heuristically, concurrent dispatches cannot claim each other's runs.
+	claimedRunsMutex sync.Mutex
+	claimedRuns      = map[int64]bool{}
+	refMutexes       = map[string]*sync.Mutex{}
+)
+
+func lockRef(spec Spec) func() {
+	claimedRunsMutex.Lock()
+	key := fmt.Sprintf("%s/%s/%s@%s", spec.Repository.Host, spec.Repository.Owner, spec.Repository.Name, spec.Ref)
+	refMutex, ok := refMutexes[key]
+	if !ok {
+		refMutex = &sync.Mutex{}
+		refMutexes[key] = refMutex
+	}
+	claimedRunsMutex.Unlock()
+	refMutex.Lock()
+	return refMutex.Unlock
+}
+
+func excludedRuns() map[int64]bool {
+	claimedRunsMutex.Lock()
+	defer claimedRunsMutex.Unlock()
+	excluded := map[int64]bool{}
+	for id := range claimedRuns {
+		excluded[id] = true
+	}
+	return excluded
+}
+
+func claimRun(id int64) {
+	claimedRunsMutex.Lock()
+	defer claimedRunsMutex.Unlock()
+	claimedRuns[id] = true
+}
+
+// ErrSkipped is the error of results for specs that were never dispatched because an earlier failure stopped the rollout.
+var ErrSkipped = errors.New("Skipped.")
+
+type Result struct {
+	Spec Spec
+	Run  *run.WorkflowRun
+	Err  error
+}
+
+func (result Result) Succeeded() bool {
+	return result.Err == nil && result.Run != nil && result.Run.Conclusion == "success"
+}
+
+func (spec Spec) String() string {
+	return fmt.Sprintf("%s on %s/%s@%s", spec.Workflow, spec.Repository.Owner, spec.Repository.Name, refs.ShortName(spec.Ref))
+}
+
+// FormatInputs renders the inputs as a stable, comma separated list of `key=value` pairs.
+func (spec Spec) FormatInputs() string {
+	keys := []string{}
+	for key := range spec.Inputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, spec.Inputs[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// WithWorkflow returns a copy of the spec for a ref and workflow that have already been resolved, so that dispatching it does not have to look them up again.
+func (spec Spec) WithWorkflow(workflowData workflow.Workflow) Spec {
+	spec.Workflow = workflowData.Name
+	spec.workflowData = &workflowData
+	return spec
+}
+
+// Resolve looks up the full ref and the workflow of the spec in the repository.
+func Resolve(spec Spec) (Spec, error) {
+	if spec.workflowData != nil {
+		return spec, nil
+	}
+
+	var err error
+	if spec.Ref == "" {
+		spec.Ref, err = default_ref.GetDefaultRef(spec.Repository)
+	} else {
+		spec.Ref, err = refs.Resolve(spec.Repository, spec.Ref)
+	}
+	if err != nil {
+		return spec, err
+	}
+
+	locator := locator.RemoteLocator{
+		Repository: spec.Repository,
+		Ref:        spec.Ref,
+	}
+	workflows, err := locator.ListWorkflows()
+	if err != nil {
+		return spec, errors.Wrap(err, "Failed to list workflows in repository.")
+	}
+	workflows = workflow.OnlyDispatchable(workflows)
+	workflowName, err := resolver.ResolveWorkflow(spec.Repository, workflows, spec.Workflow)
+	if err != nil {
+		return spec, err
+	}
+	return spec.WithWorkflow(workflows[workflowName]), nil
+}
+
+// RequiresConfirmation checks the spec against its repository's dispatch policy and returns whether the policy requires the dispatch to be confirmed.
+func RequiresConfirmation(spec Spec) (bool, error) {
+	spec, err := Resolve(spec)
+	if err != nil {
+		return false, err
+	}
+	return policy.Enforce(spec.Repository, policy.Dispatch{Ref: spec.Ref, Workflow: *spec.workflowData, Inputs: spec.Inputs})
+}
+
+// Dispatch dispatches the workflow described by the spec and returns the run it created.
+func Dispatch(spec Spec) (*run.WorkflowRun, error) {
+	spec, err := Resolve(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := map[string]string{}
+	for key, value := range spec.Inputs {
+		inputFound := false
+		for _, input := range spec.workflowData.Inputs {
+			if input.Name == key {
+				inputFound = true
+			}
+		}
+		if !inputFound {
+			return nil, errors.Errorf("Input %s not accepted by workflow.", key)
+		}
+		inputs[key] = value
+	}
+
+	correlationInput, err := correlation.DetectInput(*spec.workflowData, spec.CorrelationInput)
+	if err != nil {
+		return nil, err
+	}
+	correlationID := inputs[correlationInput]
+	if correlationInput != "" && correlationID == "" {
+		correlationID, err = correlation.NewID()
+		if err != nil {
+			return nil, err
+		}
+		inputs[correlationInput] = correlationID
+	}
+
+	needsConfirmation, err := policy.Enforce(spec.Repository, policy.Dispatch{Ref: spec.Ref, Workflow: *spec.workflowData, Inputs: inputs})
+	if err != nil {
+		return nil, err
+	}
+	if needsConfirmation && !spec.Confirmed {
+		return nil, errors.Errorf("The dispatch policy of %s/%s requires %s to be confirmed, so it can only be dispatched interactively.", spec.Repository.Owner, spec.Repository.Name, spec.Workflow)
+	}
+
+	defer lockRef(spec)()
+	runDetails, err := dispatcher.DispatchWorkflow(spec.Repository, spec.Ref, spec.Workflow, inputs)
+	var possiblyDispatched *dispatcher.PossiblyDispatchedError
+	if errors.As(err, &possiblyDispatched) {
+		log.Warnf("%s Looking for a run of %s rather than dispatching it again.", err, spec)
+	} else if err != nil {
+		return nil, err
+	}
+	var workflowRun *run.WorkflowRun
+	if runDetails != nil {
+		workflowRun, err = run.GetRun(spec.Repository, runDetails.WorkflowRunID)
+	} else {
+		workflowRun, err = run.LocateRun(spec.Repository, run.Query{Reference: spec.Ref, Workflow: spec.Workflow, CorrelationID: correlationID, ExcludedRunIDs: excludedRuns()})
+	}
+	if err != nil {
+		if possiblyDispatched != nil {
+			return nil, errors.Errorf("%s was possibly dispatched, but no run could be found for it. Check the repository's Actions tab before dispatching it again.", spec)
+		}
+		return nil, err
+	}
+	claimRun(workflowRun.ID)
+	record := history.NewRecord(spec.Repository, spec.Ref, spec.Workflow, inputs, workflowRun)
+	record.CorrelationInput = correlationInput
+	history.Add(record)
+	return workflowRun, nil
+}
+
+// DispatchAll dispatches every spec and optionally waits for the resulting runs to complete, with at most `parallelism` specs in flight at once.
+// The results are returned in the same order as the specs.
+func DispatchAll(specs []Spec, parallelism int, watch bool) []Result {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	results := make([]Result, len(specs))
+	semaphore := make(chan struct{}, parallelism)
+	waitGroup := sync.WaitGroup{}
+	for index, spec := range specs {
+		waitGroup.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+			results[index] = dispatchAndWait(spec, watch)
+		}()
+	}
+	waitGroup.Wait()
+	return results
+}
+
+func dispatchAndWait(spec Spec, watch bool) Result {
+	result := Result{Spec: spec}
+	spec, err := Resolve(spec)
+	if err != nil {
+		log.Errorf("Unable to resolve %s: %s", result.Spec, err)
+		result.Err = err
+		return result
+	}
+	result.Spec = spec
+	workflowRun, err := Dispatch(spec)
+	if err != nil {
+		log.Errorf("Unable to dispatch %s: %s", spec, err)
+		result.Err = err
+		return result
+	}
+	log.Infof("Dispatched %s as %s.", spec, workflowRun.HTMLURL)
+	result.Run = workflowRun
+	if !watch {
+		return result
+	}
+
+	workflowRun, err = run.WaitForRun(spec.Repository, workflowRun.ID)
+	if err != nil {
+		log.Errorf("Unable to watch %s: %s", spec, err)
+		result.Err = err
+		return result
+	}
+	history.AddConclusion(spec.Repository, workflowRun)
+	log.Infof("%s completed with conclusion %s.", spec, workflowRun.Conclusion)
+	result.Run = workflowRun
+	return result
+}
@@ -0,0 +1,88 @@
+package target
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/chrisgavin/gh-dispatch/internal/workflow"
+	"github.com/pkg/errors"
+)
+
+type Target struct {
+	Hostname   string
+	Repository string
+	Ref        string
+	Workflow   string
+}
+
+func IsURL(argument string) bool {
+	return strings.HasPrefix(argument, "https://") || strings.HasPrefix(argument, "http://")
+}
+
+// ParseURL extracts the repository, ref and workflow from a URL to a repository, a branch, a workflow file or a workflow's Actions page.
+func ParseURL(rawURL string) (*Target, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse URL.")
+	}
+	if parsedURL.Host == "" {
+		return nil, errors.Errorf("URL %s has no hostname.", rawURL)
+	}
+
+	parts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("URL %s does not point at a repository.", rawURL)
+	}
+	target := Target{
+		Hostname:   parsedURL.Hostname(),
+		Repository: parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"),
+	}
+
+	rest := parts[2:]
+	if len(rest) == 0 {
+		return &target, nil
+	}
+	switch rest[0] {
+	case "actions":
+		if len(rest) == 3 && rest[1] == "workflows" {
+			target.Workflow = rest[2]
+			return &target, nil
+		}
+	case "tree":
+		if len(rest) > 1 {
+			target.Ref = strings.Join(rest[1:], "/")
+			return &target, nil
+		}
+	case "blob":
+		// Branch names can contain slashes, so the only reliable way to split the ref from the file path is to look for the workflows directory.
+		blobPath := strings.Join(rest[1:], "/")
+		index := strings.Index(blobPath, "/"+workflow.WorkflowsPath+"/")
+		if index > 0 {
+			target.Ref = blobPath[:index]
+			target.Workflow = blobPath[index+len(workflow.WorkflowsPath)+2:]
+			if target.Workflow != "" && !strings.Contains(target.Workflow, "/") {
+				return &target, nil
+			}
+		}
+		return nil, errors.Errorf("URL %s does not point at a workflow file.", rawURL)
+	}
+	return nil, errors.Errorf("URL %s does not point at a repository, branch or workflow.", rawURL)
+}
+
+// ParseRunURL extracts the repository and run ID from a URL to a workflow run, or to one of its attempts or jobs.
+func ParseRunURL(rawURL string) (*Target, int64, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Unable to parse URL.")
+	}
+	parts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if parsedURL.Host == "" || len(parts) < 5 || parts[2] != "actions" || parts[3] != "runs" {
+		return nil, 0, errors.Errorf("URL %s does not point at a workflow run.", rawURL)
+	}
+	runID, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		return nil, 0, errors.Errorf("URL %s does not point at a workflow run.", rawURL)
+	}
+	return &Target{Hostname: parsedURL.Hostname(), Repository: parts[0] + "/" + parts[1]}, runID, nil
+}
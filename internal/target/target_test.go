@@ -0,0 +1,54 @@
+package target
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepositoryURL(t *testing.T) {
+	target, err := ParseURL("https://github.com/owner/repo")
+	require.NoError(t, err)
+	require.Equal(t, Target{Hostname: "github.com", Repository: "owner/repo"}, *target)
+}
+
+func TestParseWorkflowURL(t *testing.T) {
+	target, err := ParseURL("https://github.example.com/org/repo/actions/workflows/release.yml")
+	require.NoError(t, err)
+	require.Equal(t, Target{Hostname: "github.example.com", Repository: "org/repo", Workflow: "release.yml"}, *target)
+}
+
+func TestParseBlobURL(t *testing.T) {
+	target, err := ParseURL("https://github.com/owner/repo/blob/feature/some-branch/.github/workflows/deploy.yaml")
+	require.NoError(t, err)
+	require.Equal(t, Target{Hostname: "github.com", Repository: "owner/repo", Ref: "feature/some-branch", Workflow: "deploy.yaml"}, *target)
+}
+
+func TestParseTreeURL(t *testing.T) {
+	target, err := ParseURL("https://github.com/owner/repo/tree/main")
+	require.NoError(t, err)
+	require.Equal(t, Target{Hostname: "github.com", Repository: "owner/repo", Ref: "main"}, *target)
+}
+
+func TestParseUnsupportedURL(t *testing.T) {
+	_, err := ParseURL("https://github.com/owner/repo/blob/main/README.md")
+	require.Error(t, err)
+	_, err = ParseURL("https://github.com/owner")
+	require.Error(t, err)
+	_, err = ParseURL("https://github.com/owner/repo/pulls")
+	require.Error(t, err)
+}
+
+func TestParseRunURL(t *testing.T) {
+	target, runID, err := ParseRunURL("https://github.example.com/org/repo/actions/runs/1234/job/5678")
+	require.NoError(t, err)
+	require.Equal(t, Target{Hostname: "github.example.com", Repository: "org/repo"}, *target)
+	require.Equal(t, int64(1234), runID)
+}
+
+func TestParseInvalidRunURL(t *testing.T) {
+	_, _, err := ParseRunURL("https://github.com/org/repo/actions/workflows/release.yml")
+	require.Error(t, err)
+	_, _, err = ParseRunURL("https://github.com/org/repo/actions/runs/latest")
+	require.Error(t, err)
+}
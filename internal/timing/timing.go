@@ -0,0 +1,35 @@
+package timing
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// zonedLayouts are tried before localLayouts, which are interpreted in the local time zone.
+var zonedLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+}
+
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseTime parses an absolute time given by the user, such as `2026-10-18T02:00Z`.
+func ParseTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range zonedLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("Unable to parse time %s. Use a format such as 2026-10-18T02:00Z or 2026-10-18 02:00.", value)
+}
@@ -0,0 +1,30 @@
+package timing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseZonedTime(t *testing.T) {
+	parsed, err := ParseTime("2026-10-18T02:00Z", time.Local)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), parsed.UTC())
+
+	parsed, err = ParseTime("2026-10-18T02:00:30+02:00", time.Local)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 18, 0, 0, 30, 0, time.UTC), parsed.UTC())
+}
+
+func TestParseLocalTime(t *testing.T) {
+	location := time.FixedZone("Test", -5*60*60)
+	parsed, err := ParseTime("2026-10-18 02:00", location)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC), parsed.UTC())
+}
+
+func TestParseInvalidTime(t *testing.T) {
+	_, err := ParseTime("tomorrow", time.Local)
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -33,12 +34,17 @@ type Input struct {
 }
 
 type Workflow struct {
-	Name         string
-	Dispatchable bool
-	Inputs       []Input
+	Name                   string
+	DisplayName            string
+	Dispatchable           bool
+	Inputs                 []Input
+	RepositoryDispatchable bool
+	// RepositoryDispatchTypes are the event types the workflow is restricted to. If it is empty then any repository_dispatch event triggers the workflow.
+	RepositoryDispatchTypes []string
 }
 
 const workflowDispatch = "workflow_dispatch"
+const repositoryDispatch = "repository_dispatch"
 
 type workflowDispatchTrigger struct {
 	Inputs yaml.Node `yaml:"inputs"`
@@ -61,17 +67,41 @@ func ReadWorkflow(name string, rawWorkflow []byte) (*Workflow, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to parse workflow as YAML.")
 	}
+	// A workflow with an empty or null name is shown by GitHub with its file name, which callers fall back to when there is no display name.
+	if displayName, ok := parsed["name"].(string); ok && displayName != "" {
+		workflow.DisplayName = displayName
+	}
 	if on, ok := parsed["on"]; ok {
 		switch typedOn := on.(type) {
 		case string:
 			workflow.Dispatchable = on == workflowDispatch
+			workflow.RepositoryDispatchable = on == repositoryDispatch
 		case []interface{}:
 			for _, event := range typedOn {
 				if event == workflowDispatch {
 					workflow.Dispatchable = true
 				}
+				if event == repositoryDispatch {
+					workflow.RepositoryDispatchable = true
+				}
 			}
 		case map[string]interface{}:
+			if repositoryDispatchTrigger, ok := typedOn[repositoryDispatch]; ok {
+				workflow.RepositoryDispatchable = true
+				if typedRepositoryDispatchTrigger, ok := repositoryDispatchTrigger.(map[string]interface{}); ok {
+					switch types := typedRepositoryDispatchTrigger["types"].(type) {
+					case nil:
+					case string:
+						workflow.RepositoryDispatchTypes = []string{types}
+					case []interface{}:
+						for _, eventType := range types {
+							workflow.RepositoryDispatchTypes = append(workflow.RepositoryDispatchTypes, fmt.Sprintf("%v", eventType))
+						}
+					default:
+						return nil, errors.Errorf("Repository dispatch types had unexpected type %T.", types)
+					}
+				}
+			}
 			// We want to preserve the order of inputs, so in this case we re-parse the workflow using the internal types specifically meant for preserving order.
 			typedParsedWorkflow := workflowInternal{}
 			err = yaml.Unmarshal(rawWorkflow, &typedParsedWorkflow)
@@ -159,3 +189,58 @@ func ReadWorkflow(name string, rawWorkflow []byte) (*Workflow, error) {
 	}
 	return &workflow, nil
 }
+
+// OnlyDispatchable filters the workflows down to those that can be triggered with a workflow_dispatch event.
+func OnlyDispatchable(workflows map[string]Workflow) map[string]Workflow {
+	dispatchable := map[string]Workflow{}
+	for name, workflow := range workflows {
+		if workflow.Dispatchable {
+			dispatchable[name] = workflow
+		}
+	}
+	return dispatchable
+}
+
+// RepositoryDispatchTypes returns the event types declared by any of the workflows, and whether any of the workflows accepts events of any type.
+func RepositoryDispatchTypes(workflows map[string]Workflow) ([]string, bool) {
+	seen := map[string]bool{}
+	types := []string{}
+	anyType := false
+	for _, workflow := range workflows {
+		if !workflow.RepositoryDispatchable {
+			continue
+		}
+		if len(workflow.RepositoryDispatchTypes) == 0 {
+			anyType = true
+		}
+		for _, eventType := range workflow.RepositoryDispatchTypes {
+			if !seen[eventType] {
+				seen[eventType] = true
+				types = append(types, eventType)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types, anyType
+}
+
+// TriggeredBy returns the names of the workflows that a repository_dispatch event of the given type triggers, in alphabetical order.
+func TriggeredBy(workflows map[string]Workflow, eventType string) []string {
+	names := []string{}
+	for name, workflow := range workflows {
+		if !workflow.RepositoryDispatchable {
+			continue
+		}
+		triggered := len(workflow.RepositoryDispatchTypes) == 0
+		for _, declaredEventType := range workflow.RepositoryDispatchTypes {
+			if declaredEventType == eventType {
+				triggered = true
+			}
+		}
+		if triggered {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
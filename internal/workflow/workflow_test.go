@@ -151,3 +151,64 @@ on:
 	require.Equal(t, 1, len(workflowData.Inputs))
 	require.Equal(t, "foo", workflowData.Inputs[0].Default)
 }
+
+func TestReadWorkflowWithDisplayName(t *testing.T) {
+	const workflowContent = `
+name: Some Workflow
+on: workflow_dispatch
+`
+	workflowData := parseTestWorkflow(t, workflowContent)
+	require.Equal(t, "Some Workflow", workflowData.DisplayName)
+}
+
+func TestReadWorkflowWithEmptyDisplayName(t *testing.T) {
+	for _, workflowContent := range []string{"name:\non: workflow_dispatch\n", "name: ~\non: workflow_dispatch\n", "name: \"\"\non: workflow_dispatch\n"} {
+		workflowData := parseTestWorkflow(t, workflowContent)
+		require.Equal(t, "", workflowData.DisplayName)
+	}
+}
+
+func TestReadRepositoryDispatchWorkflowSingletonStyle(t *testing.T) {
+	const workflowContent = `
+on: repository_dispatch
+`
+	workflowData := parseTestWorkflow(t, workflowContent)
+	require.False(t, workflowData.Dispatchable)
+	require.True(t, workflowData.RepositoryDispatchable)
+	require.Empty(t, workflowData.RepositoryDispatchTypes)
+}
+
+func TestReadRepositoryDispatchWorkflowWithTypes(t *testing.T) {
+	const workflowContent = `
+on:
+  workflow_dispatch:
+  repository_dispatch:
+    types: [deploy, rollback]
+`
+	workflowData := parseTestWorkflow(t, workflowContent)
+	require.True(t, workflowData.Dispatchable)
+	require.True(t, workflowData.RepositoryDispatchable)
+	require.Equal(t, []string{"deploy", "rollback"}, workflowData.RepositoryDispatchTypes)
+}
+
+func TestRepositoryDispatchTypes(t *testing.T) {
+	workflows := map[string]Workflow{
+		"a.yml": {RepositoryDispatchable: true, RepositoryDispatchTypes: []string{"rollback", "deploy"}},
+		"b.yml": {RepositoryDispatchable: true, RepositoryDispatchTypes: []string{"deploy"}},
+		"c.yml": {Dispatchable: true},
+	}
+	types, anyType := RepositoryDispatchTypes(workflows)
+	require.Equal(t, []string{"deploy", "rollback"}, types)
+	require.False(t, anyType)
+}
+
+func TestTriggeredBy(t *testing.T) {
+	workflows := map[string]Workflow{
+		"a.yml": {RepositoryDispatchable: true, RepositoryDispatchTypes: []string{"rollback", "deploy"}},
+		"b.yml": {RepositoryDispatchable: true, RepositoryDispatchTypes: []string{"rollback"}},
+		"c.yml": {RepositoryDispatchable: true},
+		"d.yml": {Dispatchable: true},
+	}
+	require.Equal(t, []string{"a.yml", "c.yml"}, TriggeredBy(workflows, "deploy"))
+	require.Equal(t, []string{"c.yml"}, TriggeredBy(workflows, "build"))
+}